@@ -0,0 +1,150 @@
+package riteaid
+
+import (
+	"context"
+	"sync"
+)
+
+// Default number of searches SearchMany runs at the same time
+const DefaultConcurrency = 4
+
+// Query is a single search request used by SearchMany.
+type Query struct {
+	Address string
+	Radius  float64
+	Options SearchOptions
+}
+
+// QueryResult is the outcome of one Query sent on the SearchMany channel.
+//
+// Stores already sent in an earlier QueryResult of the same SearchMany call
+// are removed from Result.Data.Stores so every store is delivered once.
+// Duplicates holds the number of stores removed this way.
+type QueryResult struct {
+	Query      Query  // The originating query
+	Index      int    // Position of the query in the slice given to SearchMany
+	Result     Result // The search result, Data.Stores deduplicated
+	Err        error  // Error returned by the search, if any
+	Duplicates int    // Number of stores removed as already delivered
+}
+
+// WithConcurrency sets the number of searches SearchMany runs at the same
+// time. Values under 1 use DefaultConcurrency.
+func WithConcurrency(workers int) Option {
+	return func(c *Client) {
+		c.concurrency = workers
+	}
+}
+
+// SearchMany runs the queries using DefaultClient. See Client.SearchMany.
+func SearchMany(ctx context.Context, queries []Query) <-chan QueryResult {
+	return DefaultClient.SearchMany(ctx, queries)
+}
+
+// SearchMany runs the queries with a bounded pool of workers (see
+// WithConcurrency) and streams a QueryResult for each of them, in completion
+// order, on the returned channel. The channel is closed once every query has
+// been answered or ctx is done.
+//
+// Stores found by more than one query are only delivered with the first
+// result to complete, matched by StoreNumber.
+//
+// The caller must drain the channel or cancel ctx, otherwise the workers
+// block forever.
+//
+//	queries := []riteaid.Query{
+//		{Address: "Willard, OH", Radius: 10},
+//		{Address: "Norwalk, OH", Radius: 10},
+//	}
+//	for qr := range client.SearchMany(ctx, queries) {
+//		if qr.Err != nil {
+//			log.Printf("%s: %v", qr.Query.Address, qr.Err)
+//			continue
+//		}
+//		for _, store := range qr.Result.Data.Stores {
+//			fmt.Println(store.StoreNumber)
+//		}
+//	}
+func (c *Client) SearchMany(ctx context.Context, queries []Query) <-chan QueryResult {
+	workers := c.concurrency
+	if workers < 1 {
+		workers = DefaultConcurrency
+	}
+	if workers > len(queries) {
+		workers = len(queries)
+	}
+
+	results := make(chan QueryResult)
+	jobs := make(chan int)
+	dedup := newStoreDeduper()
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				qr := QueryResult{Query: queries[i], Index: i}
+				qr.Result, qr.Err = c.SearchWithOptions(ctx, queries[i].Address, queries[i].Radius, queries[i].Options)
+				// Ambiguous addresses come with stores and an error
+				if len(qr.Result.Data.Stores) > 0 {
+					qr.Result.Data.Stores, qr.Duplicates = dedup.filter(qr.Result.Data.Stores)
+				}
+
+				select {
+				case results <- qr:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	// Feed the workers, stopping early if the caller gives up
+	go func() {
+		defer close(jobs)
+		for i := range queries {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// Private type tracking the stores already delivered by StoreNumber.
+type storeDeduper struct {
+	mu   sync.Mutex
+	seen map[uint32]bool
+}
+
+// Private function returning an empty storeDeduper.
+func newStoreDeduper() *storeDeduper {
+	return &storeDeduper{seen: make(map[uint32]bool)}
+}
+
+// Private method returning a copy of stores without those already seen, and
+// the number removed. The input slice is never modified as it may be shared
+// with a Cache.
+func (d *storeDeduper) filter(stores []Store) ([]Store, int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	unique := make([]Store, 0, len(stores))
+	for _, store := range stores {
+		if d.seen[store.StoreNumber] {
+			continue
+		}
+		d.seen[store.StoreNumber] = true
+		unique = append(unique, store)
+	}
+	return unique, len(stores) - len(unique)
+}
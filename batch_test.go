@@ -0,0 +1,94 @@
+package riteaid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSearchMany(t *testing.T) {
+	var inFlight, maxInFlight int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+
+		// Every search returns store 1 plus a store numbered after the address
+		switch address := r.URL.Query().Get("address"); address {
+		case "fail":
+			w.Write([]byte(`{"Status":"FAILURE","ErrMsg":"bad address"}`))
+		case "ambiguous":
+			w.Write([]byte(`{"Status":"SUCCESS","data":{"stores":[{"storeNumber":1},{"storeNumber":50}],"ambiguousAddresses":[{"locality":"Willard"},{"locality":"Willard Bay"}]}}`))
+		default:
+			fmt.Fprintf(w, `{"Status":"SUCCESS","data":{"stores":[{"storeNumber":1},{"storeNumber":%s}]}}`, address)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURL(srv.URL), WithConcurrency(2), WithRetryPolicy(NoRetry))
+	queries := []Query{{Address: "10", Radius: 5}, {Address: "20", Radius: 5}, {Address: "fail", Radius: 5}, {Address: "30", Radius: 5}, {Address: "40", Radius: 5}, {Address: "ambiguous", Radius: 5}}
+
+	stores := map[uint32]int{}
+	duplicates := 0
+	failed := 0
+	seen := map[int]bool{}
+	for qr := range client.SearchMany(context.Background(), queries) {
+		if seen[qr.Index] || queries[qr.Index].Address != qr.Query.Address {
+			t.Errorf("QueryResult.Index = %d for %+v, want unique matching index", qr.Index, qr.Query)
+		}
+		seen[qr.Index] = true
+
+		if qr.Err != nil && !errors.Is(qr.Err, ErrAmbiguousAddress) {
+			failed++
+			continue
+		}
+		duplicates += qr.Duplicates
+		for _, store := range qr.Result.Data.Stores {
+			stores[store.StoreNumber]++
+		}
+	}
+
+	if len(seen) != len(queries) {
+		t.Errorf("SearchMany() returned %d results, want %d", len(seen), len(queries))
+	}
+	if failed != 1 {
+		t.Errorf("SearchMany() returned %d errors, want 1", failed)
+	}
+	if len(stores) != 6 || duplicates != 4 {
+		t.Errorf("SearchMany() stores = %v with %d duplicates, want 6 unique stores and 4 duplicates", stores, duplicates)
+	}
+	for number, count := range stores {
+		if count != 1 {
+			t.Errorf("store %d delivered %d times, want 1", number, count)
+		}
+	}
+	if maxInFlight > 2 {
+		t.Errorf("SearchMany() ran %d searches at once, want at most 2", maxInFlight)
+	}
+}
+
+func TestSearchManyCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testStoreJSON))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := NewClient(WithBaseURL(srv.URL), WithConcurrency(1))
+	results := client.SearchMany(ctx, []Query{{Address: "a", Radius: 1}, {Address: "b", Radius: 1}, {Address: "c", Radius: 1}})
+
+	// Take one result then walk away; the channel must still close
+	<-results
+	cancel()
+	for range results {
+	}
+}
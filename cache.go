@@ -0,0 +1,451 @@
+package riteaid
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache stores successful search results keyed by CacheKey. Implementations
+// must be safe for concurrent use. Expiry is decided by the Client using its
+// CachePolicy, a Cache only needs to remember when an entry was stored.
+type Cache interface {
+	// Get returns the entry stored for key, if any.
+	Get(key string) (CacheEntry, bool)
+
+	// Set stores entry for key replacing any previous entry.
+	Set(key string, entry CacheEntry)
+
+	// Delete removes the entry for key, if any.
+	Delete(key string)
+}
+
+// CacheEntry is a cached search Result and the time it was retrieved.
+type CacheEntry struct {
+	Result Result    `json:"result"`
+	Stored time.Time `json:"stored"`
+}
+
+// CachePolicy controls how long cached results are used.
+//
+// An entry younger than TTL is fresh and returned without calling the API.
+// An entry older than TTL but younger than TTL + StaleWhileRevalidate is
+// stale, it is still returned right away while a single background request
+// refreshes it. Anything older is fetched again before returning.
+type CachePolicy struct {
+	// How long a result is fresh. 0 means results never expire.
+	TTL time.Duration
+
+	// How long past TTL a stale result may still be returned while it is
+	// refreshed in the background. 0 disables stale results.
+	StaleWhileRevalidate time.Duration
+}
+
+// WithCache sets the cache used by SearchContext, and therefore by
+// GetStoreData when set on DefaultClient.
+//
+//	riteaid.DefaultClient = riteaid.NewClient(riteaid.WithCache(
+//		riteaid.NewMemoryCache(500),
+//		riteaid.CachePolicy{TTL: 8 * time.Hour, StaleWhileRevalidate: time.Hour},
+//	))
+func WithCache(cache Cache, policy CachePolicy) Option {
+	return func(c *Client) {
+		c.cache = cache
+		c.cachePolicy = policy
+	}
+}
+
+// Private type used as the context key for BypassCache
+type bypassCacheKey struct{}
+
+// BypassCache returns a context that makes a search skip reading the cache.
+// The fresh result is still written to the cache, so this is also a way to
+// force a refresh.
+//
+//	result, err := client.SearchContext(riteaid.BypassCache(ctx), address, 0.1)
+func BypassCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassCacheKey{}, true)
+}
+
+// Private function reporting if ctx was created by BypassCache.
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassCacheKey{}).(bool)
+	return bypass
+}
+
+// InvalidateCache removes the cached results for the given search, if any,
+// whatever SearchOptions they were made with.
+func (c *Client) InvalidateCache(address string, radius float64) {
+	if c.cache == nil {
+		return
+	}
+	for _, pharmacyOnly := range []bool{false, true} {
+		for _, zipOptional := range []bool{false, true} {
+			opts := SearchOptions{PharmacyOnly: pharmacyOnly, GlobalZipCodeOptional: zipOptional}
+			c.cache.Delete(opts.cacheKey(address, radius))
+		}
+	}
+}
+
+// CacheKey returns the key a search made with the default SearchOptions is
+// cached under. The address is normalized so trivially different spellings
+// share an entry.
+//
+//	CacheKey(" 4 Walton St E,Willard,  OH 44890", 0.1) -> "4 walton st e, willard, oh 44890|0.1"
+func CacheKey(address string, radius float64) string {
+	return fmt.Sprintf("%s|%.3g", normalizeAddress(address), radius)
+}
+
+// Private function normalizing an address for use in a cache key.
+func normalizeAddress(address string) string {
+	parts := strings.Split(strings.ToLower(address), ",")
+	for i, part := range parts {
+		parts[i] = strings.Join(strings.Fields(part), " ")
+	}
+	return strings.Trim(strings.Join(parts, ", "), ", ")
+}
+
+// Private method implementing the cache lookup for SearchContext.
+func (c *Client) cachedSearch(ctx context.Context, address string, radius float64, opts SearchOptions) (Result, error) {
+	key := opts.cacheKey(address, radius)
+
+	if !cacheBypassed(ctx) {
+		if entry, ok := c.cache.Get(key); ok {
+			age := time.Since(entry.Stored)
+			ttl := c.cachePolicy.TTL
+			switch {
+			case ttl <= 0 || age < ttl:
+				return entry.Result, nil
+			case age < ttl+c.cachePolicy.StaleWhileRevalidate:
+				c.revalidate(key, address, radius, opts)
+				return entry.Result, nil
+			}
+		}
+	}
+
+	result, err := c.search(ctx, address, radius, opts)
+	if err == nil {
+		c.cache.Set(key, CacheEntry{Result: result, Stored: time.Now()})
+	}
+	return result, err
+}
+
+// Private method that refreshes a stale cache entry in the background.
+// Only one refresh per key runs at a time.
+func (c *Client) revalidate(key string, address string, radius float64, opts SearchOptions) {
+	c.refreshMu.Lock()
+	if c.refreshing == nil {
+		c.refreshing = make(map[string]bool)
+	}
+	if c.refreshing[key] {
+		c.refreshMu.Unlock()
+		return
+	}
+	c.refreshing[key] = true
+	c.refreshMu.Unlock()
+
+	go func() {
+		defer func() {
+			c.refreshMu.Lock()
+			delete(c.refreshing, key)
+			c.refreshMu.Unlock()
+		}()
+
+		// The stale entry is kept on failure, it will expire on its own
+		result, err := c.search(context.Background(), address, radius, opts)
+		if err == nil {
+			c.cache.Set(key, CacheEntry{Result: result, Stored: time.Now()})
+		}
+	}()
+}
+
+// *****************************************************************************
+// * Memory cache
+// *****************************************************************************
+
+// MemoryCache is an in-memory least recently used Cache. Entries are copied
+// in and out, so changing a Result returned by Get does not change the cache.
+type MemoryCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+// Private type stored in the MemoryCache list
+type memoryCacheItem struct {
+	key   string
+	entry CacheEntry
+}
+
+// NewMemoryCache returns a MemoryCache holding at most capacity entries.
+// The least recently used entry is evicted when full. A capacity of 0 or
+// less means unbounded.
+func NewMemoryCache(capacity int) *MemoryCache {
+	return &MemoryCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the entry stored for key and marks it as recently used.
+func (m *MemoryCache) Get(key string) (CacheEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.items[key]
+	if !ok {
+		return CacheEntry{}, false
+	}
+	m.order.MoveToFront(element)
+	return element.Value.(*memoryCacheItem).entry.clone(), true
+}
+
+// Set stores entry for key, evicting the least recently used entry if full.
+func (m *MemoryCache) Set(key string, entry CacheEntry) {
+	entry = entry.clone()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.items[key]; ok {
+		element.Value.(*memoryCacheItem).entry = entry
+		m.order.MoveToFront(element)
+		return
+	}
+
+	m.items[key] = m.order.PushFront(&memoryCacheItem{key: key, entry: entry})
+	if m.capacity > 0 && m.order.Len() > m.capacity {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.items, oldest.Value.(*memoryCacheItem).key)
+	}
+}
+
+// Delete removes the entry for key.
+func (m *MemoryCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.items[key]; ok {
+		m.order.Remove(element)
+		delete(m.items, key)
+	}
+}
+
+// Range calls fn for every entry, most recently used first, until fn
+// returns false. Entries are not marked as used.
+func (m *MemoryCache) Range(fn func(key string, entry CacheEntry) bool) {
+	m.mu.Lock()
+	items := make([]memoryCacheItem, 0, m.order.Len())
+	for element := m.order.Front(); element != nil; element = element.Next() {
+		items = append(items, *element.Value.(*memoryCacheItem))
+	}
+	m.mu.Unlock()
+
+	for _, item := range items {
+		if !fn(item.key, item.entry.clone()) {
+			return
+		}
+	}
+}
+
+// Len returns the number of cached entries.
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
+
+// Private method returning a copy of the entry sharing no slices or maps
+// with it.
+func (e CacheEntry) clone() CacheEntry {
+	e.Result = e.Result.clone()
+	return e
+}
+
+// Private method returning a copy of the result sharing no slices or maps
+// with it.
+func (r Result) clone() Result {
+	r.Unmapped = cloneUnmapped(r.Unmapped)
+	r.Data.Unmapped = cloneUnmapped(r.Data.Unmapped)
+	r.Data.ResolvedAddress.Unmapped = cloneUnmapped(r.Data.ResolvedAddress.Unmapped)
+	if r.Data.Stores != nil {
+		stores := make([]Store, len(r.Data.Stores))
+		for i, store := range r.Data.Stores {
+			stores[i] = store.clone()
+		}
+		r.Data.Stores = stores
+	}
+	if r.Data.Warnings != nil {
+		warnings := make([]Warning, len(r.Data.Warnings))
+		for i, warning := range r.Data.Warnings {
+			warning.Unmapped = cloneUnmapped(warning.Unmapped)
+			warnings[i] = warning
+		}
+		r.Data.Warnings = warnings
+	}
+	if r.Data.AmbiguousAddresses != nil {
+		addresses := make([]ResolvedAddress, len(r.Data.AmbiguousAddresses))
+		for i, address := range r.Data.AmbiguousAddresses {
+			address.Unmapped = cloneUnmapped(address.Unmapped)
+			addresses[i] = address
+		}
+		r.Data.AmbiguousAddresses = addresses
+	}
+	return r
+}
+
+// Private method returning a copy of the store sharing no slices or maps
+// with it.
+func (s Store) clone() Store {
+	s.Unmapped = cloneUnmapped(s.Unmapped)
+	if s.SpecialServicesKeys != nil {
+		s.SpecialServicesKeys = append([]string{}, s.SpecialServicesKeys...)
+	}
+	if s.Event != nil {
+		s.Event = append(json.RawMessage{}, s.Event...)
+	}
+	if s.HolidayHours != nil {
+		holidays := make([]HolidayHours, len(s.HolidayHours))
+		for i, holiday := range s.HolidayHours {
+			holiday.Unmapped = cloneUnmapped(holiday.Unmapped)
+			holidays[i] = holiday
+		}
+		s.HolidayHours = holidays
+	}
+	pickup := &s.PickupDateAndTimes
+	pickup.Unmapped = cloneUnmapped(pickup.Unmapped)
+	if pickup.RegularHours != nil {
+		pickup.RegularHours = append([]string{}, pickup.RegularHours...)
+	}
+	if pickup.SpecialHours != nil {
+		special := make(map[string]string, len(pickup.SpecialHours))
+		for date, hours := range pickup.SpecialHours {
+			special[date] = hours
+		}
+		pickup.SpecialHours = special
+	}
+	return s
+}
+
+// Private function returning a copy of an Unmapped map and its values.
+func cloneUnmapped(unmapped map[string]json.RawMessage) map[string]json.RawMessage {
+	if unmapped == nil {
+		return nil
+	}
+	clone := make(map[string]json.RawMessage, len(unmapped))
+	for key, value := range unmapped {
+		clone[key] = append(json.RawMessage{}, value...)
+	}
+	return clone
+}
+
+// *****************************************************************************
+// * Disk cache
+// *****************************************************************************
+
+// DiskCache is a Cache storing one JSON file per entry in a directory so
+// results survive restarts. Writes are best effort, a failure to write only
+// means the next search will call the API again.
+type DiskCache struct {
+	dir string
+}
+
+// Private type written to disk by DiskCache
+type diskCacheFile struct {
+	Key string `json:"key"`
+	CacheEntry
+}
+
+// NewDiskCache returns a DiskCache storing entries in dir, creating it if
+// needed.
+//
+//	cache, err := riteaid.NewDiskCache(filepath.Join(os.TempDir(), "riteaid"))
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+// Get returns the entry stored for key.
+func (d *DiskCache) Get(key string) (CacheEntry, bool) {
+	data, err := ioutil.ReadFile(d.path(key))
+	if err != nil {
+		return CacheEntry{}, false
+	}
+
+	var file diskCacheFile
+	if err := json.Unmarshal(data, &file); err != nil || file.Key != key {
+		return CacheEntry{}, false
+	}
+	return file.CacheEntry, true
+}
+
+// Set stores entry for key. The file is written to a temporary name and
+// renamed so concurrent readers never see a partial entry.
+func (d *DiskCache) Set(key string, entry CacheEntry) {
+	data, err := json.Marshal(diskCacheFile{Key: key, CacheEntry: entry})
+	if err != nil {
+		return
+	}
+
+	tmp, err := ioutil.TempFile(d.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), d.path(key)); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// Delete removes the entry for key.
+func (d *DiskCache) Delete(key string) {
+	os.Remove(d.path(key))
+}
+
+// Range calls fn for every readable entry until fn returns false.
+func (d *DiskCache) Range(fn func(key string, entry CacheEntry) bool) {
+	names, err := filepath.Glob(filepath.Join(d.dir, "*.json"))
+	if err != nil {
+		return
+	}
+	for _, name := range names {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		var file diskCacheFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			continue
+		}
+		if !fn(file.Key, file.CacheEntry) {
+			return
+		}
+	}
+}
+
+// Private method returning the file name used for key.
+func (d *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,161 @@
+package riteaid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheKey(t *testing.T) {
+	want := "4 walton st e, willard, oh 44890|0.1"
+	for _, address := range []string{
+		"4 Walton St E, Willard, OH 44890",
+		" 4 Walton St E,Willard,  OH 44890",
+		"4  WALTON ST E , WILLARD , OH 44890 ",
+	} {
+		if got := CacheKey(address, 0.1); got != want {
+			t.Errorf("CacheKey(%q, 0.1) = %q, want %q", address, got, want)
+		}
+	}
+}
+
+func TestMemoryCacheLRU(t *testing.T) {
+	cache := NewMemoryCache(2)
+	cache.Set("a", CacheEntry{Result: Result{Status: "a"}})
+	cache.Set("b", CacheEntry{Result: Result{Status: "b"}})
+
+	// Touch "a" so "b" is the least recently used
+	cache.Get("a")
+	cache.Set("c", CacheEntry{Result: Result{Status: "c"}})
+
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("Get(b) found an entry, want it evicted")
+	}
+	if entry, ok := cache.Get("a"); !ok || entry.Result.Status != "a" {
+		t.Errorf("Get(a) = %+v, %t, want entry a", entry, ok)
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", cache.Len())
+	}
+
+	cache.Delete("a")
+	if _, ok := cache.Get("a"); ok {
+		t.Errorf("Get(a) after Delete found an entry")
+	}
+}
+
+func TestMemoryCacheCopies(t *testing.T) {
+	cache := NewMemoryCache(10)
+	result := Result{Data: Data{Stores: []Store{{
+		Name:               "cached",
+		HolidayHours:       []HolidayHours{{StoreHours: "Closed"}},
+		PickupDateAndTimes: PickupDateAndTimes{SpecialHours: map[string]string{"2022-05-30": "1:00 PM-5:00 PM"}},
+	}}}}
+	cache.Set("key", CacheEntry{Result: result})
+
+	// Neither the stored result nor a returned one is shared with the cache
+	result.Data.Stores[0].Name = "mutated before Get"
+	entry, _ := cache.Get("key")
+	store := &entry.Result.Data.Stores[0]
+	store.Name = "mutated by caller"
+	store.HolidayHours[0].StoreHours = "mutated by caller"
+	store.PickupDateAndTimes.SpecialHours["2022-05-30"] = "mutated by caller"
+
+	entry, _ = cache.Get("key")
+	store = &entry.Result.Data.Stores[0]
+	if store.Name != "cached" || store.HolidayHours[0].StoreHours != "Closed" || store.PickupDateAndTimes.SpecialHours["2022-05-30"] != "1:00 PM-5:00 PM" {
+		t.Errorf("Get(key) = %+v, want the stored store unchanged", *store)
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	cache, err := NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDiskCache() ERROR: %q", err)
+	}
+
+	stored := time.Date(2022, 5, 29, 12, 0, 0, 0, time.UTC)
+	cache.Set("key", CacheEntry{Result: Result{Status: "SUCCESS", Data: Data{Stores: []Store{{StoreNumber: 3357}}}}, Stored: stored})
+
+	entry, ok := cache.Get("key")
+	if !ok || !entry.Stored.Equal(stored) || len(entry.Result.Data.Stores) != 1 || entry.Result.Data.Stores[0].StoreNumber != 3357 {
+		t.Errorf("Get(key) = %+v, %t, want stored entry", entry, ok)
+	}
+
+	cache.Delete("key")
+	if _, ok := cache.Get("key"); ok {
+		t.Errorf("Get(key) after Delete found an entry")
+	}
+}
+
+func TestClientCache(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(testStoreJSON))
+	}))
+	defer srv.Close()
+
+	cache := NewMemoryCache(10)
+	client := NewClient(WithBaseURL(srv.URL), WithCache(cache, CachePolicy{TTL: time.Hour, StaleWhileRevalidate: time.Hour}))
+	address := "4 Walton St E, Willard, OH 44890"
+
+	// Second call with a differently spelled address is served from the cache
+	for _, a := range []string{address, "4 walton st e,  willard, oh 44890"} {
+		if _, err := client.Search(a, 0.1); err != nil {
+			t.Fatalf("Search(%q) ERROR: %q", a, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Search() made %d calls, want 1", calls)
+	}
+
+	// Bypassing calls the API
+	if _, err := client.SearchContext(BypassCache(context.Background()), address, 0.1); err != nil {
+		t.Fatalf("SearchContext(<bypass>) ERROR: %q", err)
+	}
+	if calls != 2 {
+		t.Errorf("SearchContext(<bypass>) made %d calls, want 2", calls)
+	}
+
+	// A stale entry is returned right away and refreshed in the background
+	key := CacheKey(address, 0.1)
+	stale := time.Now().Add(-90 * time.Minute)
+	cache.Set(key, CacheEntry{Result: Result{Status: "SUCCESS"}, Stored: stale})
+	result, err := client.Search(address, 0.1)
+	if err != nil || len(result.Data.Stores) != 0 {
+		t.Errorf("Search(<stale>) = %+v, %v, want the stale result", result, err)
+	}
+	for i := 0; i < 100; i++ {
+		if entry, _ := cache.Get(key); entry.Stored.After(stale) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if entry, _ := cache.Get(key); len(entry.Result.Data.Stores) != 1 {
+		t.Errorf("stale entry was not refreshed: %+v", entry)
+	}
+
+	// Changing a cached result does not change the next one
+	before := atomic.LoadInt32(&calls)
+	if result, err = client.Search(address, 0.1); err != nil || len(result.Data.Stores) != 1 {
+		t.Fatalf("Search(<cached>) = %+v, %v", result, err)
+	}
+	result.Data.Stores[0].Name = "mutated by caller"
+	result, err = client.Search(address, 0.1)
+	if calls := atomic.LoadInt32(&calls); calls != before {
+		t.Errorf("Search(<cached>) made %d calls, want none", calls-before)
+	}
+	if err != nil || len(result.Data.Stores) != 1 || result.Data.Stores[0].Name == "mutated by caller" {
+		t.Errorf("Search(<mutated>) = %+v, %v, want the cached store unchanged", result, err)
+	}
+
+	// Invalidated entries are fetched again
+	client.InvalidateCache(address, 0.1)
+	if _, ok := cache.Get(key); ok {
+		t.Errorf("InvalidateCache() left the entry in the cache")
+	}
+}
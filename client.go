@@ -0,0 +1,369 @@
+package riteaid
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default user agent sent with every request made by a Client
+const DefaultUserAgent = "RiteAidStoreSearch (+https://github.com/zinthose/RiteAidStoreSearch)"
+
+// Default maximum size of a response body, a 25 mile result is well under
+// a megabyte
+const DefaultMaxBodySize = 8 << 20
+
+// Private maximum number of unread body bytes drained before closing a
+// response so its connection can be reused
+const maxDrainBytes = 4 << 10
+
+// Error returned when a response body is larger than allowed by
+// WithMaxBodySize
+var ErrBodyTooLarge = errors.New("response body exceeds the maximum size")
+
+// DefaultClient is the Client used by the package level functions such as
+// GetStoreData and GetStoreDataJSON. It may be replaced to change the
+// behavior of those functions globally.
+//
+//	riteaid.DefaultClient = riteaid.NewClient(riteaid.WithHTTPClient(&http.Client{Timeout: 10 * time.Second}))
+var DefaultClient = NewClient()
+
+// Client is a reusable RiteAid API client. A Client is safe for concurrent
+// use by multiple goroutines and should be reused rather than created for
+// every call.
+//
+//	client := riteaid.NewClient(
+//	    riteaid.WithHTTPClient(&http.Client{Timeout: 10 * time.Second}),
+//	    riteaid.WithUserAgent("my-tool/1.0"),
+//	)
+//	result, err := client.SearchContext(ctx, "4 Walton St E, Willard, OH 44890", 0.1)
+type Client struct {
+	httpClient  *http.Client
+	baseURL     string
+	userAgent   string
+	header      http.Header
+	maxBodySize int64
+	retry       RetryPolicy
+	limiter     Limiter
+
+	concurrency int
+
+	cache       Cache
+	cachePolicy CachePolicy
+	refreshMu   sync.Mutex
+	refreshing  map[string]bool
+
+	index          *StoreIndex
+	indexStrategy  RefreshStrategy
+	indexMu        sync.Mutex
+	indexRefreshed time.Time
+	indexRefresh   *indexRefresh
+
+	timeZone TimeZoneResolver
+	dst      DSTPolicy
+
+	onDrift            func(DriftReport)
+	onTimeZoneMismatch func(TimeZoneMismatch)
+}
+
+// Option configures a Client. See NewClient.
+type Option func(*Client)
+
+// NewClient returns a new Client configured with the given options.
+// Without any options the client uses http.DefaultClient and the public
+// RiteAid API endpoint.
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		httpClient:  http.DefaultClient,
+		baseURL:     riteAidBaseURL,
+		userAgent:   DefaultUserAgent,
+		header:      make(http.Header),
+		maxBodySize: DefaultMaxBodySize,
+		retry:       DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithHTTPClient sets the http.Client used to place the API calls. Use this
+// to configure timeouts, proxies or a custom transport.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// WithBaseURL sets the getStores endpoint URL. This is primarily useful to
+// point the client at a test server or proxy.
+//
+//	WithBaseURL("http://127.0.0.1:8080/services/ext/v2/stores/getStores")
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "?")
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithHeader adds a default header sent with every request.
+// May be given multiple times to add multiple headers or values.
+func WithHeader(key string, value string) Option {
+	return func(c *Client) {
+		c.header.Add(key, value)
+	}
+}
+
+// WithMaxBodySize sets the largest response body the Client reads, in bytes.
+// Larger responses fail with ErrBodyTooLarge. The default is
+// DefaultMaxBodySize, values under 1 remove the limit.
+func WithMaxBodySize(max int64) Option {
+	return func(c *Client) {
+		c.maxBodySize = max
+	}
+}
+
+// SearchJSON is the same as SearchJSONContext using context.Background.
+func (c *Client) SearchJSON(address string, radius float64) (string, error) {
+	return c.SearchJSONContext(context.Background(), address, radius)
+}
+
+// SearchJSONContext places a call to the RiteAid API and returns the raw
+// store location data in JSON format. The request is canceled when ctx is
+// done.
+//
+// See GetStoreDataJSON for details on the address and radius.
+func (c *Client) SearchJSONContext(ctx context.Context, address string, radius float64) (string, error) {
+	var body []byte
+	err := c.do(ctx, address, radius, SearchOptions{}, func(resp *http.Response, r io.Reader) (err error) {
+		body, err = ioutil.ReadAll(r)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// Search is the same as SearchContext using context.Background.
+func (c *Client) Search(address string, radius float64) (Result, error) {
+	return c.SearchContext(context.Background(), address, radius)
+}
+
+// SearchContext places a call to the RiteAid API and returns the store
+// location data as a struct. The request is canceled when ctx is done.
+// If the API call fails, an *APIError wrapping ErrRiteAidAPIError will be
+// returned.
+//
+// When the client has a Cache (see WithCache) a fresh cached result is
+// returned without calling the API.
+//
+// See GetStoreData for details on the address and radius.
+func (c *Client) SearchContext(ctx context.Context, address string, radius float64) (Result, error) {
+	return c.SearchWithOptions(ctx, address, radius, SearchOptions{})
+}
+
+// SearchWithOptions is SearchContext with control over the API query
+// parameters and client side filtering of the stores. See SearchOptions.
+//
+// When the address is ambiguous the stores are returned along with an
+// *AmbiguousAddressError.
+//
+//	// Pharmacy-only stores offering service PREF-100 within 10 miles
+//	result, err := client.SearchWithOptions(ctx, address, 10, riteaid.SearchOptions{
+//		PharmacyOnly: true,
+//		Services:     []string{"PREF-100"},
+//	})
+func (c *Client) SearchWithOptions(ctx context.Context, address string, radius float64, opts SearchOptions) (Result, error) {
+	result, err := c.fetch(ctx, address, radius, opts)
+	if err != nil {
+		return result, err
+	}
+	if result.Ambiguous() {
+		return opts.apply(result), &AmbiguousAddressError{Address: address, Candidates: result.Data.AmbiguousAddresses}
+	}
+	return opts.apply(result), nil
+}
+
+// Private method returning the unfiltered result, from the cache when
+// possible. The stores are added to the StoreIndex, if any.
+func (c *Client) fetch(ctx context.Context, address string, radius float64, opts SearchOptions) (Result, error) {
+	var result Result
+	var err error
+	if c.cache == nil {
+		result, err = c.search(ctx, address, radius, opts)
+	} else {
+		result, err = c.cachedSearch(ctx, address, radius, opts)
+	}
+	if err == nil && c.index != nil {
+		c.index.AddResult(result)
+	}
+	return result, err
+}
+
+// Private method that places the API call and decodes the Result as the
+// response body is read, within the WithMaxBodySize limit. Unmapped fields
+// and API changes are collected in the same pass, only the unmapped values
+// are copied.
+func (c *Client) search(ctx context.Context, address string, radius float64, opts SearchOptions) (Result, error) {
+	var result Result
+	var drift *drifter
+	var apiErr error
+
+	// Get and decode the Store Data
+	err := c.do(ctx, address, radius, opts, func(resp *http.Response, r io.Reader) error {
+		// Only compare with the structs when API changes are reported
+		drift = nil
+		if c.onDrift != nil {
+			drift = newDrifter()
+		}
+		var err error
+		result, err = decodeResult(r, resp.StatusCode, drift)
+		if errors.As(err, new(*APIError)) {
+			// The API answered, a failed Status is not retried
+			apiErr = err
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return result, err
+	}
+	if apiErr != nil {
+		return result, apiErr
+	}
+
+	// Report API changes
+	if drift != nil {
+		if report := drift.sorted(); !report.Empty() {
+			c.onDrift(report)
+		}
+	}
+	if c.onTimeZoneMismatch != nil {
+		for _, store := range result.Data.Stores {
+			if mismatch, ok := CheckTimeZone(store); ok {
+				c.onTimeZoneMismatch(mismatch)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Private method that places a getStores call, retrying transient failures
+// according to the client's RetryPolicy. read is called with the response
+// and its size limited body on every 2xx response; an error it returns is
+// retried like a failed request. A non 2xx HTTP status is returned as an
+// *APIError.
+func (c *Client) do(ctx context.Context, address string, radius float64, opts SearchOptions, read func(resp *http.Response, body io.Reader) error) error {
+	url, err := buildStoreDataURL(c.baseURL, address, radius, opts)
+	if err != nil && err != ErrRadiusOverMax {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := c.doOnce(ctx, url, read)
+		if err == nil || !c.retry.shouldRetry(attempt, err) {
+			return err
+		}
+
+		delay := c.retry.delay(attempt, err)
+		if c.retry.OnRetry != nil {
+			c.retry.OnRetry(RetryEvent{Attempt: attempt, Err: err, Delay: delay})
+		}
+		if sleepErr := sleepContext(ctx, delay); sleepErr != nil {
+			// Report the failure that caused the retry, not the cancellation
+			return err
+		}
+	}
+}
+
+// Private method that places a single getStores call once the rate
+// limiter, if any, allows it, and hands a successful response to read.
+func (c *Client) doOnce(ctx context.Context, url string, read func(resp *http.Response, body io.Reader) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx, req.URL.Host); err != nil {
+			return err
+		}
+	}
+	for key, values := range c.header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	// Make the request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// Drain what is left, within reason, so the connection can be reused
+		io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxDrainBytes))
+		resp.Body.Close()
+	}()
+	body := limitBody(resp.Body, c.maxBodySize)
+
+	// Anything other than 2xx is not a usable store search result
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		excerpt, _ := ioutil.ReadAll(io.LimitReader(body, maxErrorBodyExcerpt))
+		return newHTTPError(resp, excerpt)
+	}
+
+	return read(resp, body)
+}
+
+// Private type limiting how much of a response body can be read, see
+// WithMaxBodySize.
+type limitedBody struct {
+	r         io.Reader
+	remaining int64
+}
+
+// Private function returning body limited to max bytes. max under 1 means
+// no limit.
+func limitBody(body io.Reader, max int64) io.Reader {
+	if max < 1 {
+		return body
+	}
+	return &limitedBody{r: body, remaining: max}
+}
+
+// Read reads from the body, failing with ErrBodyTooLarge once more than the
+// limit has been received.
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrBodyTooLarge
+	}
+	// Read one byte past the limit to tell a body of exactly max bytes apart
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n - 1, ErrBodyTooLarge
+	}
+	return n, err
+}
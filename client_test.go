@@ -0,0 +1,105 @@
+package riteaid
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const testStoreJSON = `{"data":{"stores":[{"storeNumber":3357,"address":"4 East Walton Street","city":"Willard","state":"OH","fullZipCode":"44890-9419","name":"Rite Aid","latitude":41.0428,"longitude":-82.7258}]},"Status":"SUCCESS"}`
+
+func TestClientSearch(t *testing.T) {
+	var gotQuery, gotAgent, gotHeader string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		gotAgent = r.Header.Get("User-Agent")
+		gotHeader = r.Header.Get("X-Test")
+		w.Write([]byte(testStoreJSON))
+	}))
+	defer srv.Close()
+
+	client := NewClient(
+		WithBaseURL(srv.URL),
+		WithUserAgent("riteaid-test"),
+		WithHeader("X-Test", "yes"),
+	)
+
+	result, err := client.Search("4 Walton St E, Willard, OH 44890", 3.1)
+	if err != nil {
+		t.Fatalf("Search() ERROR: %q", err)
+	}
+	if len(result.Data.Stores) != 1 || result.Data.Stores[0].StoreNumber != 3357 {
+		t.Errorf("Search() = %+v, want store 3357", result.Data.Stores)
+	}
+
+	want := "pharmacyOnly=false&globalZipCodeRequired=true&address=4+Walton+St+E%2C+Willard%2C+OH+44890&radius=3.1"
+	if gotQuery != want {
+		t.Errorf("Search() query = %q, want %q", gotQuery, want)
+	}
+	if gotAgent != "riteaid-test" {
+		t.Errorf("Search() User-Agent = %q, want %q", gotAgent, "riteaid-test")
+	}
+	if gotHeader != "yes" {
+		t.Errorf("Search() X-Test = %q, want %q", gotHeader, "yes")
+	}
+
+	// Raw JSON is returned untouched
+	raw, err := client.SearchJSON("4 Walton St E, Willard, OH 44890", 3.1)
+	if err != nil || raw != testStoreJSON {
+		t.Errorf("SearchJSON() = %q, %v, want %q", raw, err, testStoreJSON)
+	}
+
+	// Radius validation happens before any request is made
+	if _, err := client.Search("4 Walton St E, Willard, OH 44890", -1); err != ErrRadiusUnderMin {
+		t.Errorf("Search(<address>, -1) ERROR = %v, want %v", err, ErrRadiusUnderMin)
+	}
+}
+
+func TestClientSearchContextCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURL(srv.URL))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.SearchContext(ctx, "Willard, OH", 1); err == nil {
+		t.Errorf("SearchContext(<canceled>) ERROR = nil, want context error")
+	}
+}
+
+func TestClientMaxBodySize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testStoreJSON))
+	}))
+	defer srv.Close()
+
+	// A body of exactly the limit is read
+	client := NewClient(WithBaseURL(srv.URL), WithMaxBodySize(int64(len(testStoreJSON))))
+	if raw, err := client.SearchJSON("Willard, OH", 1); err != nil || raw != testStoreJSON {
+		t.Errorf("SearchJSON(<limit = body>) = %q, %v, want the body", raw, err)
+	}
+
+	// One byte less fails without retrying
+	client = NewClient(WithBaseURL(srv.URL), WithMaxBodySize(int64(len(testStoreJSON)-1)))
+	if _, err := client.Search("Willard, OH", 1); !errors.Is(err, ErrBodyTooLarge) || IsRetryable(err) {
+		t.Errorf("Search(<limit < body>) ERROR = %v, want %v", err, ErrBodyTooLarge)
+	}
+	if _, err := client.SearchJSON("Willard, OH", 1); !errors.Is(err, ErrBodyTooLarge) {
+		t.Errorf("SearchJSON(<limit < body>) ERROR = %v, want %v", err, ErrBodyTooLarge)
+	}
+
+	// No limit
+	client = NewClient(WithBaseURL(srv.URL), WithMaxBodySize(0))
+	if _, err := client.Search("Willard, OH", 1); err != nil {
+		t.Errorf("Search(<no limit>) ERROR: %q", err)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+
+	"github.com/zinthose/RiteAidStoreSearch/internal/tzmap"
+)
+
+// Private type of the parts of a GeoJSON FeatureCollection that are used
+type featureCollection struct {
+	Features []struct {
+		Properties struct {
+			TZID string `json:"tzid"`
+		} `json:"properties"`
+		Geometry struct {
+			Type        string          `json:"type"`
+			Coordinates json.RawMessage `json:"coordinates"`
+		} `json:"geometry"`
+	} `json:"features"`
+}
+
+// Private function reading a GeoJSON FeatureCollection of Polygon and
+// MultiPolygon features into a Map, one Area per polygon.
+func readGeoJSON(path string, simplify float64) (*tzmap.Map, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseGeoJSON(data, simplify)
+}
+
+// Private function implementing readGeoJSON on the file contents.
+func parseGeoJSON(data []byte, simplify float64) (*tzmap.Map, error) {
+	var fc featureCollection
+	if err := json.Unmarshal(data, &fc); err != nil {
+		return nil, err
+	}
+
+	m := &tzmap.Map{Source: "GeoJSON"}
+	zones := make(map[string]uint16)
+	var names []string // zone of each area
+	for i, feature := range fc.Features {
+		name := feature.Properties.TZID
+		if name == "" {
+			return nil, fmt.Errorf("feature %d has no tzid", i)
+		}
+		if _, ok := zones[name]; !ok {
+			zones[name] = 0
+			m.Zones = append(m.Zones, name)
+		}
+
+		var polygons [][][][2]float64
+		switch feature.Geometry.Type {
+		case "Polygon":
+			var polygon [][][2]float64
+			if err := json.Unmarshal(feature.Geometry.Coordinates, &polygon); err != nil {
+				return nil, fmt.Errorf("feature %d (%s): %v", i, name, err)
+			}
+			polygons = append(polygons, polygon)
+		case "MultiPolygon":
+			if err := json.Unmarshal(feature.Geometry.Coordinates, &polygons); err != nil {
+				return nil, fmt.Errorf("feature %d (%s): %v", i, name, err)
+			}
+		default:
+			return nil, fmt.Errorf("feature %d (%s): unsupported geometry %q", i, name, feature.Geometry.Type)
+		}
+
+		for _, polygon := range polygons {
+			area := tzmap.Area{Box: tzmap.Box{Min: tzmap.Point{Lat: math.MaxInt32, Lng: math.MaxInt32}, Max: tzmap.Point{Lat: math.MinInt32, Lng: math.MinInt32}}}
+			for _, ring := range polygon {
+				points := simplifyRing(ring, simplify)
+				if len(points) < 3 {
+					continue
+				}
+				for _, p := range points {
+					area.Box.Min.Lat = min32(area.Box.Min.Lat, p.Lat)
+					area.Box.Min.Lng = min32(area.Box.Min.Lng, p.Lng)
+					area.Box.Max.Lat = max32(area.Box.Max.Lat, p.Lat+1)
+					area.Box.Max.Lng = max32(area.Box.Max.Lng, p.Lng+1)
+				}
+				area.Rings = append(area.Rings, points)
+			}
+			if len(area.Rings) > 0 {
+				m.Areas = append(m.Areas, area)
+				names = append(names, name)
+			}
+		}
+	}
+
+	// Number the zones alphabetically so the output does not depend on the
+	// order of the features
+	sort.Strings(m.Zones)
+	for i, name := range m.Zones {
+		zones[name] = uint16(i)
+	}
+	for i := range m.Areas {
+		m.Areas[i].Zone = zones[names[i]]
+	}
+	return m, nil
+}
+
+// Private function converting a GeoJSON ring of [longitude, latitude]
+// positions to Points, dropping the closing position and, with a tolerance
+// above 0, the points the Douglas-Peucker algorithm finds unnecessary.
+func simplifyRing(ring [][2]float64, tolerance float64) []tzmap.Point {
+	if n := len(ring); n > 1 && ring[0] == ring[n-1] {
+		ring = ring[:n-1]
+	}
+	keep := make([]bool, len(ring))
+	if tolerance <= 0 || len(ring) <= 4 {
+		for i := range keep {
+			keep[i] = true
+		}
+	} else {
+		keep[0], keep[len(ring)-1] = true, true
+		douglasPeucker(ring, 0, len(ring)-1, tolerance, keep)
+	}
+
+	var points []tzmap.Point
+	for i, position := range ring {
+		if keep[i] {
+			points = append(points, tzmap.NewPoint(position[1], position[0]))
+		}
+	}
+	return points
+}
+
+// Private function marking the points between first and last that are
+// farther than tolerance from the line joining them, recursively.
+func douglasPeucker(ring [][2]float64, first int, last int, tolerance float64, keep []bool) {
+	farthest, distance := 0, 0.0
+	for i := first + 1; i < last; i++ {
+		if d := lineDistance(ring[i], ring[first], ring[last]); d > distance {
+			farthest, distance = i, d
+		}
+	}
+	if distance > tolerance {
+		keep[farthest] = true
+		douglasPeucker(ring, first, farthest, tolerance, keep)
+		douglasPeucker(ring, farthest, last, tolerance, keep)
+	}
+}
+
+// Private function returning the distance in degrees from p to the segment
+// a-b.
+func lineDistance(p [2]float64, a [2]float64, b [2]float64) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	if dx == 0 && dy == 0 {
+		return math.Hypot(p[0]-a[0], p[1]-a[1])
+	}
+	t := math.Max(0, math.Min(1, ((p[0]-a[0])*dx+(p[1]-a[1])*dy)/(dx*dx+dy*dy)))
+	return math.Hypot(p[0]-a[0]-t*dx, p[1]-a[1]-t*dy)
+}
+
+// Private function returning the smaller of a and b.
+func min32(a int32, b int32) int32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Private function returning the larger of a and b.
+func max32(a int32, b int32) int32 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestParseGeoJSON(t *testing.T) {
+	data := []byte(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","properties":{"tzid":"Zone/B"},"geometry":{"type":"Polygon","coordinates":[
+			[[10,10],[20,10],[20,20],[10,20],[10,10]],
+			[[14,14],[16,14],[16,16],[14,16],[14,14]]
+		]}},
+		{"type":"Feature","properties":{"tzid":"Zone/A"},"geometry":{"type":"MultiPolygon","coordinates":[
+			[[[14,14],[16,14],[16,16],[14,16],[14,14]]],
+			[[[30,30],[31,30.5],[32,30],[32,32],[30,32],[30,30]]]
+		]}}
+	]}`)
+	m, err := parseGeoJSON(data, 0.6)
+	if err != nil {
+		t.Fatalf("parseGeoJSON() ERROR: %q", err)
+	}
+	if len(m.Zones) != 2 || m.Zones[0] != "Zone/A" || len(m.Areas) != 3 {
+		t.Fatalf("parseGeoJSON() = %v zones, %d areas, want 2 sorted zones and 3 areas", m.Zones, len(m.Areas))
+	}
+	if len(m.Areas[2].Rings[0]) != 4 {
+		t.Errorf("parseGeoJSON() kept %d points of the simplified ring, want 4", len(m.Areas[2].Rings[0]))
+	}
+
+	tests := []struct {
+		lat, lng float64
+		want     string
+	}{
+		{12, 12, "Zone/B"},
+		{15, 15, "Zone/A"},
+		{31, 31, "Zone/A"},
+		{0, 0, ""},
+	}
+	for _, test := range tests {
+		if got := m.Lookup(test.lat, test.lng); got != test.want {
+			t.Errorf("Lookup(%g, %g) = %q, want %q", test.lat, test.lng, got, test.want)
+		}
+	}
+
+	if _, err := parseGeoJSON([]byte(`{"features":[{"properties":{"tzid":"X"},"geometry":{"type":"Point","coordinates":[0,0]}}]}`), 0); err == nil {
+		t.Error("parseGeoJSON(<point>) ERROR = nil, want unsupported geometry")
+	}
+}
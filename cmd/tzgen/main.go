@@ -0,0 +1,65 @@
+// Command tzgen writes the time zone boundaries embedded by the tzmap
+// package, used by riteaid.GetTZLocation.
+//
+// The usual source is a GeoJSON release of timezone-boundary-builder
+// (https://github.com/evansiroky/timezone-boundary-builder/releases), the
+// "with oceans" variant covering the whole globe:
+//
+//	go run ./cmd/tzgen -geojson combined-with-oceans.json -simplify 0.001 -source "timezone-boundary-builder 2022b"
+//
+// The Go source of github.com/zsefvlol/timezonemapper, which the initial
+// data was converted from, is read with -timezonemapper instead.
+//
+// Run it from the module root and commit internal/tzmap/zones.bin.gz.
+// Update internal/tzmap/NOTICE with the attribution and license of the new
+// source, timezone-boundary-builder data is under the ODbL.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/zinthose/RiteAidStoreSearch/internal/tzmap"
+)
+
+func main() {
+	geojsonPath := flag.String("geojson", "", "GeoJSON `file` of zone polygons with a tzid property")
+	mapperPath := flag.String("timezonemapper", "", "timezonemapper.go `file` to convert")
+	simplify := flag.Float64("simplify", 0, "drop polygon points closer than `degrees` to the simplified line, GeoJSON only")
+	source := flag.String("source", "", "description of the data source stored with the data")
+	out := flag.String("o", "internal/tzmap/zones.bin.gz", "output `file`")
+	flag.Parse()
+
+	if (*geojsonPath == "") == (*mapperPath == "") {
+		fmt.Fprintln(os.Stderr, "tzgen: exactly one of -geojson and -timezonemapper is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var m *tzmap.Map
+	var err error
+	if *geojsonPath != "" {
+		m, err = readGeoJSON(*geojsonPath, *simplify)
+	} else {
+		m, err = readTimezoneMapper(*mapperPath)
+	}
+	if err != nil {
+		log.Fatalf("tzgen: %v", err)
+	}
+	if *source != "" {
+		m.Source = *source
+	}
+
+	var buf bytes.Buffer
+	if err := m.Encode(&buf); err != nil {
+		log.Fatalf("tzgen: %v", err)
+	}
+	if err := ioutil.WriteFile(*out, buf.Bytes(), 0644); err != nil {
+		log.Fatalf("tzgen: %v", err)
+	}
+	log.Printf("tzgen: wrote %s, %d zones in %d areas, %d bytes", *out, len(m.Zones), len(m.Areas), buf.Len())
+}
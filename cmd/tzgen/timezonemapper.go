@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/zinthose/RiteAidStoreSearch/internal/tzmap"
+)
+
+// Private type converting the decision tree of timezonemapper.go to Areas.
+// Every leaf of the tree covers a box of the globe, narrowed by the lat and
+// lng comparisons leading to it, and becomes one Area. A polygon test
+// becomes an Area with the polygon as its ring, followed by the Areas of
+// the else branch for the rest of the same box.
+type mapperConverter struct {
+	m        *tzmap.Map
+	funcs    map[string]*ast.FuncDecl
+	polygons map[int][]tzmap.Point
+}
+
+// Private function reading the Go source of
+// github.com/zsefvlol/timezonemapper into a Map.
+func readTimezoneMapper(path string) (*tzmap.Map, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	c := mapperConverter{
+		m:        &tzmap.Map{Source: "github.com/zsefvlol/timezonemapper v1.0.0"},
+		funcs:    make(map[string]*ast.FuncDecl),
+		polygons: make(map[int][]tzmap.Point),
+	}
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			c.funcs[decl.Name.Name] = decl
+		case *ast.GenDecl:
+			c.readZones(decl)
+		}
+	}
+	if len(c.m.Zones) == 0 {
+		return nil, fmt.Errorf("%s: timezoneStrings not found", path)
+	}
+	for name, fn := range c.funcs {
+		if strings.HasPrefix(name, "initializer") {
+			if err := c.readPolygons(fn); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	root, ok := c.funcs["getTzInt"]
+	if !ok {
+		return nil, fmt.Errorf("%s: getTzInt not found", path)
+	}
+	world := tzmap.Box{Min: tzmap.NewPoint(-90, -180), Max: tzmap.NewPoint(90, 180)}
+	if err := c.walk(root.Body.List, world, nil); err != nil {
+		return nil, err
+	}
+	return c.m, nil
+}
+
+// Private method reading the timezoneStrings array.
+func (c *mapperConverter) readZones(decl *ast.GenDecl) {
+	for _, spec := range decl.Specs {
+		value, ok := spec.(*ast.ValueSpec)
+		if !ok || len(value.Names) != 1 || value.Names[0].Name != "timezoneStrings" || len(value.Values) != 1 {
+			continue
+		}
+		list, ok := value.Values[0].(*ast.CompositeLit)
+		if !ok {
+			continue
+		}
+		for _, elt := range list.Elts {
+			if lit, ok := elt.(*ast.BasicLit); ok {
+				name, _ := strconv.Unquote(lit.Value)
+				c.m.Zones = append(c.m.Zones, name)
+			}
+		}
+	}
+}
+
+// Private method reading the "poly[i] = createtzPolygon(lat, lng, ...)"
+// statements of an initializer function.
+func (c *mapperConverter) readPolygons(fn *ast.FuncDecl) error {
+	for _, stmt := range fn.Body.List {
+		assign, ok := stmt.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+			continue
+		}
+		index, ok := assign.Lhs[0].(*ast.IndexExpr)
+		call, isCall := assign.Rhs[0].(*ast.CallExpr)
+		if !ok || !isCall {
+			continue
+		}
+		i, err := intLiteral(index.Index)
+		if err != nil {
+			return err
+		}
+		if len(call.Args)%2 != 0 {
+			return fmt.Errorf("polygon %d has an odd number of coordinates", i)
+		}
+		ring := make([]tzmap.Point, 0, len(call.Args)/2)
+		for j := 0; j < len(call.Args); j += 2 {
+			lat, err := floatLiteral(call.Args[j])
+			if err != nil {
+				return err
+			}
+			lng, err := floatLiteral(call.Args[j+1])
+			if err != nil {
+				return err
+			}
+			ring = append(ring, tzmap.NewPoint(lat, lng))
+		}
+		c.polygons[i] = ring
+	}
+	return nil
+}
+
+// Private method adding the Areas of a statement list evaluated within box.
+// Statements after an if without an else are its else branch.
+func (c *mapperConverter) walk(stmts []ast.Stmt, box tzmap.Box, rings [][]tzmap.Point) error {
+	if len(stmts) == 0 {
+		return nil
+	}
+	switch stmt := stmts[0].(type) {
+	case *ast.ReturnStmt:
+		return c.leaf(stmt, box, rings)
+
+	case *ast.BlockStmt:
+		return c.walk(stmt.List, box, rings)
+
+	case *ast.IfStmt:
+		var elseStmts []ast.Stmt
+		switch e := stmt.Else.(type) {
+		case nil:
+			elseStmts = stmts[1:]
+		case *ast.BlockStmt:
+			elseStmts = e.List
+		default:
+			elseStmts = []ast.Stmt{e}
+		}
+
+		// A polygon test, the else branch covers the rest of the box
+		if call, ok := stmt.Cond.(*ast.CallExpr); ok {
+			i, err := polygonIndex(call)
+			if err != nil {
+				return err
+			}
+			ring, ok := c.polygons[i]
+			if !ok {
+				return fmt.Errorf("polygon %d is not defined", i)
+			}
+			if err := c.walk(stmt.Body.List, box, append(rings[:len(rings):len(rings)], ring)); err != nil {
+				return err
+			}
+			return c.walk(elseStmts, box, rings)
+		}
+
+		// A "lat < v" or "lng < v" comparison splits the box
+		cond, ok := stmt.Cond.(*ast.BinaryExpr)
+		if !ok || cond.Op != token.LSS {
+			return fmt.Errorf("unexpected condition at %d", stmt.Pos())
+		}
+		ident, ok := cond.X.(*ast.Ident)
+		if !ok {
+			return fmt.Errorf("unexpected condition at %d", stmt.Pos())
+		}
+		v, err := floatLiteral(cond.Y)
+		if err != nil {
+			return err
+		}
+		split := tzmap.NewPoint(v, v)
+		below, above := box, box
+		switch ident.Name {
+		case "lat":
+			below.Max.Lat, above.Min.Lat = min32(split.Lat, box.Max.Lat), max32(split.Lat, box.Min.Lat)
+		case "lng":
+			below.Max.Lng, above.Min.Lng = min32(split.Lng, box.Max.Lng), max32(split.Lng, box.Min.Lng)
+		default:
+			return fmt.Errorf("unexpected variable %q at %d", ident.Name, stmt.Pos())
+		}
+		if err := c.walk(stmt.Body.List, below, rings); err != nil {
+			return err
+		}
+		return c.walk(elseStmts, above, rings)
+	}
+	return fmt.Errorf("unexpected statement at %d", stmts[0].Pos())
+}
+
+// Private method adding the Area of a "return n" or "return callN(lat, lng)"
+// leaf.
+func (c *mapperConverter) leaf(stmt *ast.ReturnStmt, box tzmap.Box, rings [][]tzmap.Point) error {
+	if len(stmt.Results) != 1 {
+		return fmt.Errorf("unexpected return at %d", stmt.Pos())
+	}
+	if call, ok := stmt.Results[0].(*ast.CallExpr); ok {
+		ident, ok := call.Fun.(*ast.Ident)
+		if !ok || c.funcs[ident.Name] == nil {
+			return fmt.Errorf("unexpected call at %d", stmt.Pos())
+		}
+		return c.walk(c.funcs[ident.Name].Body.List, box, rings)
+	}
+
+	zone, err := intLiteral(stmt.Results[0])
+	if err != nil {
+		return err
+	}
+	if zone < 0 || zone >= len(c.m.Zones) {
+		return fmt.Errorf("zone %d out of range at %d", zone, stmt.Pos())
+	}
+	if box.Min.Lat >= box.Max.Lat || box.Min.Lng >= box.Max.Lng {
+		return nil
+	}
+	if len(rings) > 1 {
+		return fmt.Errorf("nested polygon tests at %d are not supported", stmt.Pos())
+	}
+	c.m.Areas = append(c.m.Areas, tzmap.Area{Zone: uint16(zone), Box: box, Rings: rings})
+	return nil
+}
+
+// Private function returning i of a "poly[i].Contains(lat, lng)" call.
+func polygonIndex(call *ast.CallExpr) (int, error) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Contains" {
+		return 0, fmt.Errorf("unexpected call at %d", call.Pos())
+	}
+	index, ok := sel.X.(*ast.IndexExpr)
+	if !ok {
+		return 0, fmt.Errorf("unexpected call at %d", call.Pos())
+	}
+	return intLiteral(index.Index)
+}
+
+// Private function returning the value of an integer literal.
+func intLiteral(expr ast.Expr) (int, error) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.INT {
+		return 0, fmt.Errorf("expected an integer at %d", expr.Pos())
+	}
+	return strconv.Atoi(lit.Value)
+}
+
+// Private function returning the value of a possibly negative number
+// literal.
+func floatLiteral(expr ast.Expr) (float64, error) {
+	sign := 1.0
+	if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.SUB {
+		sign, expr = -1, unary.X
+	}
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || (lit.Kind != token.FLOAT && lit.Kind != token.INT) {
+		return 0, fmt.Errorf("expected a number at %d", expr.Pos())
+	}
+	v, err := strconv.ParseFloat(lit.Value, 64)
+	return sign * v, err
+}
@@ -0,0 +1,629 @@
+package riteaid
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DriftReport lists the differences between a JSON response and the Go
+// structs it is decoded into. Paths use "." between fields, "[]" for array
+// elements and ".*" for map values, i.e. "data.stores[].event".
+type DriftReport struct {
+	New         []FieldDrift // Fields in the JSON that no struct field maps
+	Missing     []FieldDrift // Fields of the structs, without omitempty, absent from the JSON
+	TypeChanged []FieldDrift // Fields whose JSON type does not match the Go type
+}
+
+// FieldDrift is a single difference found by SchemaDrift. Want is the JSON
+// type the Go struct expects and Got the JSON type received, each one of
+// "string", "number", "boolean", "object" or "array". Want is empty for new
+// fields and Got is empty for missing fields.
+type FieldDrift struct {
+	Path string
+	Want string
+	Got  string
+}
+
+// Empty reports whether no drift was found.
+func (r DriftReport) Empty() bool {
+	return len(r.New) == 0 && len(r.Missing) == 0 && len(r.TypeChanged) == 0
+}
+
+// String returns the report with one difference per line.
+//
+//	new field data.ambiguousAddresses (array)
+//	missing field data.stores[].timeZone (string)
+//	changed type of data.stores[].storeNumber from number to string
+func (r DriftReport) String() string {
+	var lines []string
+	for _, f := range r.New {
+		lines = append(lines, fmt.Sprintf("new field %s (%s)", f.Path, f.Got))
+	}
+	for _, f := range r.Missing {
+		lines = append(lines, fmt.Sprintf("missing field %s (%s)", f.Path, f.Want))
+	}
+	for _, f := range r.TypeChanged {
+		lines = append(lines, fmt.Sprintf("changed type of %s from %s to %s", f.Path, f.Want, f.Got))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SchemaDrift compares a raw getStores response against Result and reports
+// the fields RiteAid added, removed or changed the type of. Fields set to
+// null are never reported as changed. Each path is reported once, however
+// many stores it appears in.
+//
+//	report, err := riteaid.SchemaDrift([]byte(jsonData))
+//	if err == nil && !report.Empty() {
+//		log.Printf("RiteAid API changed:\n%s", report)
+//	}
+func SchemaDrift(data []byte) (DriftReport, error) {
+	d := newDrifter()
+	if err := walkJSON(bytes.NewReader(data), reflect.TypeOf(Result{}), reflect.Value{}, d); err != nil {
+		return DriftReport{}, err
+	}
+	return d.sorted(), nil
+}
+
+// WithDriftHandler sets a function called with the DriftReport of every
+// successful API response that does not match the Go structs. Responses
+// served from a Cache are not checked again.
+//
+//	riteaid.WithDriftHandler(func(report riteaid.DriftReport) {
+//		log.Printf("RiteAid API changed:\n%s", report)
+//	})
+func WithDriftHandler(fn func(DriftReport)) Option {
+	return func(c *Client) {
+		c.onDrift = fn
+	}
+}
+
+// Private type collecting a DriftReport without repeating paths
+type drifter struct {
+	report DriftReport
+	seen   map[string]bool
+}
+
+// Private function returning an empty drifter.
+func newDrifter() *drifter {
+	return &drifter{seen: make(map[string]bool)}
+}
+
+// Private method recording a difference once per path and kind. A nil
+// drifter records nothing.
+func (d *drifter) add(kind string, drift FieldDrift) {
+	if d == nil || d.seen[kind+"|"+drift.Path] {
+		return
+	}
+	d.seen[kind+"|"+drift.Path] = true
+	switch kind {
+	case "new":
+		d.report.New = append(d.report.New, drift)
+	case "missing":
+		d.report.Missing = append(d.report.Missing, drift)
+	case "type":
+		d.report.TypeChanged = append(d.report.TypeChanged, drift)
+	}
+}
+
+// Private method returning the report with each list sorted by path.
+func (d *drifter) sorted() DriftReport {
+	for _, list := range [][]FieldDrift{d.report.New, d.report.Missing, d.report.TypeChanged} {
+		sort.Slice(list, func(i, j int) bool { return list[i].Path < list[j].Path })
+	}
+	return d.report
+}
+
+// Private type decoding a JSON document into a Go value one token at a
+// time, see walkJSON.
+type jsonWalker struct {
+	dec     *json.Decoder
+	drift   *drifter
+	typeErr error
+}
+
+// Private function decoding the JSON document read from r into v, of type
+// t, in a single pass over r. Object keys no struct field maps are stored
+// in the Unmapped field of the matching struct of v and reported to d, when
+// not nil. Only those values are copied, the rest is decoded straight into
+// v. When v is not valid the document is only compared with t.
+//
+// Like json.Unmarshal a value of the wrong type is skipped and the first
+// one is returned as an *json.UnmarshalTypeError once the document is read.
+func walkJSON(r io.Reader, t reflect.Type, v reflect.Value, d *drifter) error {
+	w := jsonWalker{dec: json.NewDecoder(r), drift: d}
+	w.dec.UseNumber()
+	if err := w.value("", t, v); err != nil {
+		return err
+	}
+
+	// Only white space may follow the document
+	if _, err := w.dec.Token(); err != io.EOF {
+		if err == nil {
+			err = errors.New("invalid data after the top-level JSON value")
+		}
+		return err
+	}
+	return w.typeErr
+}
+
+// Private method reading the next token, the end of the input is an
+// unexpected one as the document is not complete.
+func (w *jsonWalker) token() (json.Token, error) {
+	tok, err := w.dec.Token()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return tok, err
+}
+
+// Private method walking the next JSON value, expected to be of type t and
+// decoded into v.
+func (w *jsonWalker) value(path string, t reflect.Type, v reflect.Value) error {
+	// Types decoding themselves, i.e. json.RawMessage, are given the value
+	if decodesItself(t) {
+		if v.IsValid() {
+			return w.dec.Decode(v.Addr().Interface())
+		}
+		var raw json.RawMessage
+		return w.dec.Decode(&raw)
+	}
+
+	tok, err := w.token()
+	if err != nil {
+		return err
+	}
+	got := tokenKind(tok)
+	if got == "null" {
+		if v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Map || v.Kind() == reflect.Slice) {
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		if v.IsValid() {
+			if v.IsNil() {
+				v.Set(reflect.New(t))
+			}
+			v = v.Elem()
+		}
+	}
+
+	want := goJSONKind(t)
+	if want == "" {
+		return w.skip(tok)
+	}
+	_, fromString := stringStructs[t]
+	if got != want && !(got == "string" && fromString) {
+		w.drift.add("type", FieldDrift{Path: path, Want: want, Got: got})
+		w.typeError(v, got, t, path)
+		return w.skip(tok)
+	}
+
+	switch {
+	case got == "object" && t.Kind() == reflect.Struct:
+		return w.object(path, t, v)
+
+	case got == "object":
+		if v.IsValid() && v.IsNil() {
+			v.Set(reflect.MakeMap(t))
+		}
+		for w.dec.More() {
+			tok, err := w.token()
+			if err != nil {
+				return err
+			}
+			var elem reflect.Value
+			if v.IsValid() {
+				elem = reflect.New(t.Elem()).Elem()
+			}
+			if err := w.value(path+".*", t.Elem(), elem); err != nil {
+				return err
+			}
+			if v.IsValid() {
+				key, _ := tok.(string)
+				v.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), elem)
+			}
+		}
+
+	case got == "array":
+		if v.IsValid() && t.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(t, 0, 0))
+		}
+		for i := 0; w.dec.More(); i++ {
+			var elem reflect.Value
+			if v.IsValid() && t.Kind() == reflect.Slice {
+				v.Set(reflect.Append(v, reflect.Zero(t.Elem())))
+				elem = v.Index(i)
+			} else if v.IsValid() && i < v.Len() {
+				elem = v.Index(i)
+			}
+			if err := w.value(path+"[]", t.Elem(), elem); err != nil {
+				return err
+			}
+		}
+
+	case got == "string" && t.Kind() == reflect.Struct:
+		if v.IsValid() {
+			v.FieldByName(stringStructs[t]).SetString(tok.(string))
+		}
+		return nil
+
+	default:
+		w.set(path, t, v, tok)
+		return nil
+	}
+
+	// The closing delimiter
+	_, err = w.token()
+	return err
+}
+
+// Private method storing a string, number or boolean in v.
+func (w *jsonWalker) set(path string, t reflect.Type, v reflect.Value, tok json.Token) {
+	if !v.IsValid() {
+		return
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(tok.(string))
+	case reflect.Bool:
+		v.SetBool(tok.(bool))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(tok.(json.Number).String(), 10, 64)
+		if err != nil || v.OverflowInt(n) {
+			w.typeError(v, "number "+tok.(json.Number).String(), t, path)
+			return
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(tok.(json.Number).String(), 10, 64)
+		if err != nil || v.OverflowUint(n) {
+			w.typeError(v, "number "+tok.(json.Number).String(), t, path)
+			return
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(tok.(json.Number).String(), v.Type().Bits())
+		if err != nil {
+			w.typeError(v, "number "+tok.(json.Number).String(), t, path)
+			return
+		}
+		v.SetFloat(n)
+	}
+}
+
+// Private method recording the first value that does not fit the Go type,
+// when decoding into v.
+func (w *jsonWalker) typeError(v reflect.Value, got string, t reflect.Type, path string) {
+	if v.IsValid() && w.typeErr == nil {
+		w.typeErr = &json.UnmarshalTypeError{Value: got, Type: t, Offset: w.dec.InputOffset(), Field: path}
+	}
+}
+
+// Private method walking the fields of an object decoded into v, a struct
+// of type t, after its opening delimiter.
+func (w *jsonWalker) object(path string, t reflect.Type, v reflect.Value) error {
+	fields := jsonFields(t)
+	present := make(map[string]bool, len(fields))
+	var unmapped map[string]json.RawMessage
+	for w.dec.More() {
+		tok, err := w.token()
+		if err != nil {
+			return err
+		}
+		key, _ := tok.(string)
+		present[strings.ToLower(key)] = true
+
+		if field, ok := fields[strings.ToLower(key)]; ok {
+			var fv reflect.Value
+			if v.IsValid() {
+				fv = v.Field(field.index)
+			}
+			if err := w.value(joinPath(path, key), field.typ, fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var raw json.RawMessage
+		if err := w.dec.Decode(&raw); err != nil {
+			return err
+		}
+		if unmapped == nil {
+			unmapped = make(map[string]json.RawMessage)
+		}
+		unmapped[key] = raw
+		w.drift.add("new", FieldDrift{Path: joinPath(path, key), Got: jsonKind(raw)})
+	}
+	if _, err := w.token(); err != nil {
+		return err
+	}
+
+	if w.drift != nil {
+		for key, field := range fields {
+			if !present[key] && !field.omitEmpty {
+				w.drift.add("missing", FieldDrift{Path: joinPath(path, field.name), Want: goJSONKind(field.typ)})
+			}
+		}
+	}
+	if v.IsValid() {
+		if field := v.FieldByName("Unmapped"); field.CanSet() && field.Type() == reflect.TypeOf(unmapped) {
+			field.Set(reflect.ValueOf(unmapped))
+		}
+	}
+	return nil
+}
+
+// Private method skipping the rest of the value starting with tok.
+func (w *jsonWalker) skip(tok json.Token) error {
+	if delim, ok := tok.(json.Delim); !ok || delim == '}' || delim == ']' {
+		return nil
+	}
+	for depth := 1; depth > 0; {
+		tok, err := w.token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+	return nil
+}
+
+// Private function returning the JSON type of the value starting with tok.
+func tokenKind(tok json.Token) string {
+	switch tok := tok.(type) {
+	case json.Delim:
+		if tok == '{' {
+			return "object"
+		}
+		return "array"
+	case string:
+		return "string"
+	case json.Number, float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return "null"
+}
+
+// Private function joining a field name to a drift path.
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// Private function returning the JSON type of a raw value.
+func jsonKind(raw json.RawMessage) string {
+	trimmed := strings.TrimLeft(string(raw), " \t\r\n")
+	if trimmed == "" {
+		return "null"
+	}
+	switch trimmed[0] {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "boolean"
+	case 'n':
+		return "null"
+	}
+	return "number"
+}
+
+// Private table of the structs that also decode from a JSON string, stored
+// in the named field
+var stringStructs = map[reflect.Type]string{
+	reflect.TypeOf(Warning{}): "Message",
+}
+
+// Private interface type implemented by the types decoding themselves
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// Private function reporting whether values of type t are decoded by
+// encoding/json rather than walked, i.e. json.RawMessage and time.Time.
+// Result and Warning have their own UnmarshalJSON which walks them.
+func decodesItself(t reflect.Type) bool {
+	if t == reflect.TypeOf(Result{}) || t == reflect.TypeOf(Warning{}) {
+		return false
+	}
+	return t.Kind() == reflect.Interface || reflect.PtrTo(t).Implements(unmarshalerType)
+}
+
+// Private function returning the JSON type a Go type decodes from, or an
+// empty string when any JSON type is accepted.
+func goJSONKind(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if decodesItself(t) {
+		return ""
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return ""
+}
+
+// *****************************************************************************
+// * Unmapped fields
+// *****************************************************************************
+
+// Private type describing a struct field as seen by encoding/json
+type jsonField struct {
+	name      string
+	index     int
+	typ       reflect.Type
+	omitEmpty bool
+}
+
+// Private cache of jsonFields by reflect.Type
+var jsonFieldsCache sync.Map
+
+// Private function returning the JSON fields of a struct type keyed by their
+// lower case name, as encoding/json matches keys ignoring case.
+func jsonFields(t reflect.Type) map[string]jsonField {
+	if fields, ok := jsonFieldsCache.Load(t); ok {
+		return fields.(map[string]jsonField)
+	}
+
+	fields := make(map[string]jsonField)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		fields[strings.ToLower(name)] = jsonField{
+			name:      name,
+			index:     i,
+			typ:       f.Type,
+			omitEmpty: strings.Contains(","+options+",", ",omitempty,"),
+		}
+	}
+	jsonFieldsCache.Store(t, fields)
+	return fields
+}
+
+// Private function decoding data into v, a pointer, filling the Unmapped
+// fields of the structs in it.
+func unmarshalUnmapped(data []byte, v interface{}) error {
+	value := reflect.ValueOf(v).Elem()
+	return walkJSON(bytes.NewReader(data), value.Type(), value, nil)
+}
+
+// Private function decoding a Result, with its unmapped fields, as it is
+// read from r, reporting the differences with the structs to d when not
+// nil. A Status other than "SUCCESS" is returned as an *APIError with the
+// given HTTP status code.
+func decodeResult(r io.Reader, statusCode int, d *drifter) (Result, error) {
+	var result Result
+	if err := walkJSON(r, reflect.TypeOf(result), reflect.ValueOf(&result).Elem(), d); err != nil {
+		return result, err
+	}
+	if result.Status != "SUCCESS" {
+		return result, newResultError(statusCode, result)
+	}
+	return result, nil
+}
+
+// Private function encoding v, a struct, with the unmapped fields added back
+// so a Result survives a round trip through a Cache.
+func encodeUnmapped(v interface{}, unmapped map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(unmapped) == 0 {
+		return data, err
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	for key, value := range unmapped {
+		if _, ok := object[key]; !ok {
+			object[key] = value
+		}
+	}
+	return json.Marshal(object)
+}
+
+// Private alias types encoded and decoded without their methods
+type (
+	resultAlias             Result
+	dataAlias               Data
+	holidayHoursAlias       HolidayHours
+	pickupDateAndTimesAlias PickupDateAndTimes
+	resolvedAddressAlias    ResolvedAddress
+	storeAlias              Store
+	warningAlias            Warning
+)
+
+// UnmarshalJSON decodes a Result keeping the unmapped fields of the Result
+// and of every struct in it in their Unmapped field.
+func (r *Result) UnmarshalJSON(data []byte) error {
+	*r = Result{}
+	return unmarshalUnmapped(data, (*resultAlias)(r))
+}
+
+// MarshalJSON encodes a Result including its Unmapped fields.
+func (r Result) MarshalJSON() ([]byte, error) {
+	return encodeUnmapped(resultAlias(r), r.Unmapped)
+}
+
+// MarshalJSON encodes Data including its Unmapped fields.
+func (d Data) MarshalJSON() ([]byte, error) {
+	return encodeUnmapped(dataAlias(d), d.Unmapped)
+}
+
+// MarshalJSON encodes HolidayHours including its Unmapped fields.
+func (h HolidayHours) MarshalJSON() ([]byte, error) {
+	return encodeUnmapped(holidayHoursAlias(h), h.Unmapped)
+}
+
+// MarshalJSON encodes PickupDateAndTimes including its Unmapped fields.
+func (p PickupDateAndTimes) MarshalJSON() ([]byte, error) {
+	return encodeUnmapped(pickupDateAndTimesAlias(p), p.Unmapped)
+}
+
+// MarshalJSON encodes a ResolvedAddress including its Unmapped fields.
+func (a ResolvedAddress) MarshalJSON() ([]byte, error) {
+	return encodeUnmapped(resolvedAddressAlias(a), a.Unmapped)
+}
+
+// MarshalJSON encodes a Store including its Unmapped fields.
+func (s Store) MarshalJSON() ([]byte, error) {
+	return encodeUnmapped(storeAlias(s), s.Unmapped)
+}
+
+// UnmarshalJSON decodes a Warning from an object or from a plain string
+// stored in w.Message. Unmapped fields are kept when decoding a Result.
+func (w *Warning) UnmarshalJSON(data []byte) error {
+	if jsonKind(data) == "string" {
+		*w = Warning{}
+		return json.Unmarshal(data, &w.Message)
+	}
+	return json.Unmarshal(data, (*warningAlias)(w))
+}
+
+// MarshalJSON encodes a Warning including its Unmapped fields.
+func (w Warning) MarshalJSON() ([]byte, error) {
+	return encodeUnmapped(warningAlias(w), w.Unmapped)
+}
@@ -0,0 +1,192 @@
+package riteaid
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestUnmappedFields(t *testing.T) {
+	data := `{"Status":"SUCCESS","newTopLevel":1,"data":{"stores":[{"storeNumber":1,"promo":{"name":"Flu shots"}}]}}`
+
+	var result Result
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		t.Fatalf("json.Unmarshal() ERROR: %q", err)
+	}
+	if string(result.Unmapped["newTopLevel"]) != "1" {
+		t.Errorf("Result.Unmapped = %s, want newTopLevel", result.Unmapped)
+	}
+	if store := result.Data.Stores[0]; store.StoreNumber != 1 || string(store.Unmapped["promo"]) != `{"name":"Flu shots"}` {
+		t.Errorf("Store = %+v, want store 1 with an unmapped promo", store)
+	}
+	if result.Data.Unmapped != nil {
+		t.Errorf("Data.Unmapped = %s, want nil", result.Data.Unmapped)
+	}
+
+	// Unmapped fields survive a round trip, i.e. through a DiskCache
+	encoded, err := json.Marshal(result)
+	if err != nil || !strings.Contains(string(encoded), `"newTopLevel":1`) || !strings.Contains(string(encoded), `"promo":{"name":"Flu shots"}`) {
+		t.Errorf("json.Marshal() = %s, %v, want the unmapped fields", encoded, err)
+	}
+
+	// Every level is filled, whatever the order and nesting of the fields
+	nested := `{"data":{"stores":[{"holidayHours":[{"holidayDate":"2022-05-30","closedFor":"Memorial Day"}],"pickupDateAndTimes":{"specialHours":{"2022-05-30":"1:00 PM-5:00 PM"},"slots":3}},{"storeNumber":2}],"resolvedAddress":{"plusCode":"86HW+X2"},"extra":[{"a":1}]},"Status":"SUCCESS"}`
+	result = Result{}
+	if err := json.Unmarshal([]byte(nested), &result); err != nil {
+		t.Fatalf("json.Unmarshal(<nested>) ERROR: %q", err)
+	}
+	stores := result.Data.Stores
+	if string(stores[0].HolidayHours[0].Unmapped["closedFor"]) != `"Memorial Day"` || string(stores[0].PickupDateAndTimes.Unmapped["slots"]) != "3" || stores[1].Unmapped != nil {
+		t.Errorf("Stores = %+v, want the unmapped holiday and pickup fields", stores)
+	}
+	if string(result.Data.ResolvedAddress.Unmapped["plusCode"]) != `"86HW+X2"` || string(result.Data.Unmapped["extra"]) != `[{"a":1}]` || result.Unmapped != nil {
+		t.Errorf("Result = %+v, want the unmapped address and data fields", result)
+	}
+}
+
+func TestWarningsAndEvents(t *testing.T) {
+	data := `{"data":{"warnings":["plain",{"code":"W1","message":"object","extra":true}],"stores":[{"event":{"name":"Flu shots","room":"B"}},{"event":null}]}}`
+
+	var result Result
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		t.Fatalf("json.Unmarshal() ERROR: %q", err)
+	}
+	d := result.Data
+	if len(d.Warnings) != 2 || d.Warnings[0].Message != "plain" || d.Warnings[1].Code != "W1" || string(d.Warnings[1].Unmapped["extra"]) != "true" {
+		t.Errorf("Data.Warnings = %+v, want a plain and an object warning", d.Warnings)
+	}
+	if string(d.Stores[0].Event) != `{"name":"Flu shots","room":"B"}` || string(d.Stores[1].Event) != "null" {
+		t.Errorf("Store.Event = %s, %s, want the raw event and null", d.Stores[0].Event, d.Stores[1].Event)
+	}
+
+	// Both warning forms are expected, neither is drift
+	report, err := SchemaDrift([]byte(`{"Status":"SUCCESS","data":{"warnings":["plain",{"code":"W1"}]}}`))
+	if err != nil || len(report.TypeChanged) != 0 {
+		t.Errorf("SchemaDrift(<warnings>) TypeChanged = %+v, %v, want none", report.TypeChanged, err)
+	}
+}
+
+func TestSchemaDrift(t *testing.T) {
+	data := `{"Status":"SUCCESS","data":{"globalZipCode":"44890","resolvedAddress":{},"warnings":null,"stores":[
+		{"storeNumber":"3357","promo":null,"specialServicesKeys":[1]},
+		{"storeNumber":3358,"promo":null,"pickupDateAndTimes":{"specialHours":{"2022-05-28":5}}}
+	]}}`
+
+	report, err := SchemaDrift([]byte(data))
+	if err != nil {
+		t.Fatalf("SchemaDrift() ERROR: %q", err)
+	}
+	if len(report.New) != 1 || report.New[0] != (FieldDrift{Path: "data.stores[].promo", Got: "null"}) {
+		t.Errorf("SchemaDrift() New = %+v, want data.stores[].promo once", report.New)
+	}
+	want := []FieldDrift{
+		{Path: "data.stores[].pickupDateAndTimes.specialHours.*", Want: "string", Got: "number"},
+		{Path: "data.stores[].specialServicesKeys[]", Want: "string", Got: "number"},
+		{Path: "data.stores[].storeNumber", Want: "number", Got: "string"},
+	}
+	if len(report.TypeChanged) != len(want) {
+		t.Fatalf("SchemaDrift() TypeChanged = %+v, want %+v", report.TypeChanged, want)
+	}
+	for i := range want {
+		if report.TypeChanged[i] != want[i] {
+			t.Errorf("SchemaDrift() TypeChanged[%d] = %+v, want %+v", i, report.TypeChanged[i], want[i])
+		}
+	}
+
+	missing := map[string]bool{}
+	for _, f := range report.Missing {
+		missing[f.Path] = true
+	}
+	if !missing["data.stores[].timeZone"] || !missing["data.resolvedAddress.latitude"] || missing["data.stores[].holidayHours"] || missing["ErrCde"] {
+		t.Errorf("SchemaDrift() Missing = %+v, want required fields only", report.Missing)
+	}
+	if !strings.Contains(report.String(), "changed type of data.stores[].storeNumber from number to string") {
+		t.Errorf("DriftReport.String() = %q", report.String())
+	}
+}
+
+func TestDriftHandler(t *testing.T) {
+	var fixture struct {
+		Response struct {
+			Body string `json:"body"`
+		} `json:"response"`
+	}
+	data, err := ioutil.ReadFile("testdata/fixtures/4-walton-st-e-willard-oh-44890_r0.5.synthetic.json")
+	if err != nil || json.Unmarshal(data, &fixture) != nil {
+		t.Fatalf("reading fixture ERROR: %v", err)
+	}
+
+	body := fixture.Response.Body
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	var reports []DriftReport
+	client := NewClient(WithBaseURL(srv.URL), WithDriftHandler(func(report DriftReport) { reports = append(reports, report) }))
+
+	// The fixture matches the structs
+	if _, err := client.Search("4 Walton St E, Willard, OH 44890", 0.5); err != nil {
+		t.Fatalf("Search() ERROR: %q", err)
+	}
+	if len(reports) != 0 {
+		t.Fatalf("WithDriftHandler() called with %q, want no drift", reports[0])
+	}
+
+	// A field added by RiteAid is reported
+	body = strings.Replace(body, `"storeNumber":`, `"curbside":true,"storeNumber":`, 1)
+	if _, err := client.Search("4 Walton St E, Willard, OH 44890", 0.5); err != nil {
+		t.Fatalf("Search() ERROR: %q", err)
+	}
+	if len(reports) != 1 || reports[0].String() != "new field data.stores[].curbside (boolean)" {
+		t.Errorf("WithDriftHandler() reports = %q, want the curbside field", reports)
+	}
+}
+
+func TestDecodeResult(t *testing.T) {
+	var fixture struct {
+		Response struct {
+			Body string `json:"body"`
+		} `json:"response"`
+	}
+	data, err := ioutil.ReadFile("testdata/fixtures/4-walton-st-e-willard-oh-44890_r0.5.synthetic.json")
+	if err != nil || json.Unmarshal(data, &fixture) != nil {
+		t.Fatalf("reading fixture ERROR: %v", err)
+	}
+
+	// The single pass decodes what json.Unmarshal does, read a byte at a time
+	for _, body := range []string{fixture.Response.Body, `{"data":{"stores":[{"storeNumber":7,"event":{"id":1},"specialServicesKeys":null}],"warnings":["plain",{"code":"W1"}]},"Status":"SUCCESS"}`} {
+		result, err := decodeResult(iotest.OneByteReader(strings.NewReader(body)), 200, nil)
+		if err != nil {
+			t.Fatalf("decodeResult() ERROR: %q", err)
+		}
+		var want Result
+		if err := json.Unmarshal([]byte(body), (*resultAlias)(&want)); err != nil {
+			t.Fatalf("json.Unmarshal() ERROR: %q", err)
+		}
+		if !reflect.DeepEqual(result, want) {
+			t.Errorf("decodeResult() = %+v, want %+v", result, want)
+		}
+	}
+
+	// A changed type fails like json.Unmarshal, after decoding the rest
+	result, err := ParseResult(strings.NewReader(`{"data":{"stores":[{"storeNumber":"3357","city":"Willard"}]},"Status":"SUCCESS"}`))
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Field != "data.stores[].storeNumber" || result.Data.Stores[0].City != "Willard" {
+		t.Errorf("ParseResult(<changed type>) = %+v, %v, want city Willard and an *json.UnmarshalTypeError", result.Data.Stores, err)
+	}
+
+	// Only white space may follow the document
+	if _, err := ParseResult(strings.NewReader(`{"Status":"SUCCESS"} {}`)); err == nil {
+		t.Errorf("ParseResult(<trailing data>) ERROR = nil, want an error")
+	}
+	if _, err := ParseResult(strings.NewReader(`{"Status":"SUCCESS"}` + "\n")); err != nil {
+		t.Errorf("ParseResult(<trailing new line>) ERROR = %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package riteaid
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Error returned under DSTError when a store hour is skipped or repeated by
+// a daylight saving time change
+var ErrDSTTransition = errors.New("local time is skipped or repeated by a daylight saving time change")
+
+// DSTPolicy chooses the instant of a store hour that does not exist, i.e.
+// 2:30am when clocks spring forward from 2:00am to 3:00am, or that occurs
+// twice, i.e. 1:30am when clocks fall back from 2:00am to 1:00am.
+//
+//	Policy           2:30am skipped    1:30am repeated
+//	DSTEarliest      1:30am standard   1:30am daylight (the first)
+//	DSTLatest        3:30am daylight   1:30am standard (the second)
+//	DSTShiftForward  3:30am daylight   1:30am daylight (the first)
+//	DSTError         ErrDSTTransition  ErrDSTTransition
+type DSTPolicy int
+
+const (
+	// The earlier of the two readings, what time.Date does. This is the
+	// default.
+	DSTEarliest DSTPolicy = iota
+
+	// The later of the two readings
+	DSTLatest
+
+	// Skipped times move forward by the length of the gap, repeated times
+	// are the first occurrence
+	DSTShiftForward
+
+	// Skipped and repeated times return ErrDSTTransition
+	DSTError
+)
+
+// String returns "earliest", "latest", "shift forward" or "error".
+func (p DSTPolicy) String() string {
+	switch p {
+	case DSTEarliest:
+		return "earliest"
+	case DSTLatest:
+		return "latest"
+	case DSTShiftForward:
+		return "shift forward"
+	case DSTError:
+		return "error"
+	}
+	return fmt.Sprintf("DSTPolicy(%d)", int(p))
+}
+
+// WithDSTPolicy sets the DSTPolicy used by the Client's Hours. Without it
+// DSTEarliest is used.
+func WithDSTPolicy(policy DSTPolicy) Option {
+	return func(c *Client) {
+		c.dst = policy
+	}
+}
+
+// Private method returning the instant of the wall clock time t on day
+// under the policy. t may be 24:00 or later, falling on a following day.
+func (p DSTPolicy) at(t TimeOfDay, day time.Time) (time.Time, error) {
+	loc := day.Location()
+	year, month, date := day.Date()
+	wall := time.Date(year, month, date, 0, int(t), 0, 0, time.UTC)
+
+	// Read the wall clock with the offsets in effect a day before and a day
+	// after, a day holds at most one change
+	u := wall.Unix()
+	_, before := time.Unix(u-24*60*60, 0).In(loc).Zone()
+	_, after := time.Unix(u+24*60*60, 0).In(loc).Zone()
+	first, second := time.Unix(u-int64(before), 0).In(loc), time.Unix(u-int64(after), 0).In(loc)
+	if second.Before(first) {
+		first, second = second, first
+	}
+
+	firstValid, secondValid := sameWallClock(first, wall), sameWallClock(second, wall)
+	switch {
+	case first.Equal(second) || firstValid != secondValid:
+		// Not near a change
+		if secondValid {
+			return second, nil
+		}
+		return first, nil
+	case p == DSTError && firstValid:
+		return time.Time{}, fmt.Errorf("%w: %s on %s occurs twice in %s", ErrDSTTransition, t, wall.Format(DateFormat), loc)
+	case p == DSTError:
+		return time.Time{}, fmt.Errorf("%w: %s on %s does not exist in %s", ErrDSTTransition, t, wall.Format(DateFormat), loc)
+	case p == DSTLatest, p == DSTShiftForward && !firstValid:
+		return second, nil
+	}
+	return first, nil
+}
+
+// Private function returning true if the wall clock of t is wall, ignoring
+// the locations.
+func sameWallClock(t time.Time, wall time.Time) bool {
+	y1, m1, d1 := t.Date()
+	y2, m2, d2 := wall.Date()
+	return y1 == y2 && m1 == m2 && d1 == d2 && t.Hour() == wall.Hour() && t.Minute() == wall.Minute()
+}
+
+// Private method returning the start and end of an interval on day under
+// the policy.
+func (p DSTPolicy) interval(i Interval, day time.Time) (time.Time, time.Time, error) {
+	start, err := p.at(i.Start, day)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := p.at(i.End, day)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, end, nil
+}
+
+// DSTAdjustment reports an opening interval whose elapsed length differs
+// from its length on the clock because of a daylight saving time change,
+// i.e. "12:00am-6:00am" lasting 5 hours on the day clocks spring forward.
+type DSTAdjustment struct {
+	Department Department
+	Interval   Interval
+
+	// The instants the interval starts and ends under the DSTPolicy
+	Start time.Time
+	End   time.Time
+
+	// The length on the clock and the time actually elapsed
+	WallClock time.Duration
+	Elapsed   time.Duration
+}
+
+// String returns a one line description of the adjustment.
+func (a DSTAdjustment) String() string {
+	return fmt.Sprintf("%s %s lasts %s instead of %s", a.Department, a.Interval, a.Elapsed, a.WallClock)
+}
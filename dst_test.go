@@ -0,0 +1,86 @@
+package riteaid
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDSTPolicy(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("time.LoadLocation() ERROR: %q", err)
+	}
+	spring := time.Date(2022, 3, 13, 0, 0, 0, 0, loc)
+	fall := time.Date(2022, 11, 6, 0, 0, 0, 0, loc)
+	skipped, repeated := NewTimeOfDay(2, 30), NewTimeOfDay(1, 30)
+
+	tests := []struct {
+		policy   DSTPolicy
+		skipped  string
+		repeated string
+	}{
+		{DSTEarliest, "01:30 EST", "01:30 EDT"},
+		{DSTLatest, "03:30 EDT", "01:30 EST"},
+		{DSTShiftForward, "03:30 EDT", "01:30 EDT"},
+	}
+	for _, test := range tests {
+		if got, err := test.policy.at(skipped, spring); err != nil || got.Format("15:04 MST") != test.skipped {
+			t.Errorf("%s: 2:30am on %s = %s, %v, want %s", test.policy, spring.Format(DateFormat), got, err, test.skipped)
+		}
+		if got, err := test.policy.at(repeated, fall); err != nil || got.Format("15:04 MST") != test.repeated {
+			t.Errorf("%s: 1:30am on %s = %s, %v, want %s", test.policy, fall.Format(DateFormat), got, err, test.repeated)
+		}
+	}
+
+	if _, err := DSTError.at(skipped, spring); !errors.Is(err, ErrDSTTransition) {
+		t.Errorf("DSTError: 2:30am ERROR = %v, want %v", err, ErrDSTTransition)
+	}
+	if _, err := DSTError.at(repeated, fall); !errors.Is(err, ErrDSTTransition) {
+		t.Errorf("DSTError: 1:30am ERROR = %v, want %v", err, ErrDSTTransition)
+	}
+
+	// Times away from the change are never affected
+	for _, policy := range []DSTPolicy{DSTEarliest, DSTLatest, DSTShiftForward, DSTError} {
+		got, err := policy.at(NewTimeOfDay(8, 0), spring)
+		if err != nil || !got.Equal(time.Date(2022, 3, 13, 8, 0, 0, 0, loc)) {
+			t.Errorf("%s: 8:00am = %s, %v, want 8:00am EDT", policy, got, err)
+		}
+	}
+}
+
+func TestDSTHours(t *testing.T) {
+	storeData := scheduleStore()
+	storeData.TimeZone = "America/New_York"
+	storeData.StoreHoursSunday = "Open 24 Hours"
+	storeData.RXHrsSun = "2:30am-6:00pm"
+
+	// Sunday 2022-03-13 clocks spring forward at 2:00am
+	if _, _, err := (Hours{DST: DSTError}).GetStoreHours("2022-03-13", storeData); !errors.Is(err, ErrDSTTransition) {
+		t.Errorf("Hours{DSTError}.GetStoreHours() ERROR = %v, want %v", err, ErrDSTTransition)
+	}
+	_, rxHours, err := NewClient(WithDSTPolicy(DSTShiftForward)).Hours().GetStoreHours("2022-03-13", storeData)
+	if err != nil || rxHours[0].Format("3:04pm MST") != "3:30am EDT" {
+		t.Errorf("Hours{DSTShiftForward}.GetStoreHours() rx = %s, %v, want to open 3:30am EDT", rxHours, err)
+	}
+
+	adjustments, err := (Hours{DST: DSTShiftForward}).DSTAdjustments("2022-03-13", storeData)
+	if err != nil || len(adjustments) != 2 {
+		t.Fatalf("DSTAdjustments() = %v, %v, want the store and pharmacy", adjustments, err)
+	}
+	if got := adjustments[0].String(); got != "store 12:00am-12:00am lasts 23h0m0s instead of 24h0m0s" {
+		t.Errorf("DSTAdjustments()[0] = %q", got)
+	}
+	if adjustments[1].Department != DepartmentPharmacy || adjustments[1].Elapsed != 14*time.Hour+30*time.Minute {
+		t.Errorf("DSTAdjustments()[1] = %s, want the pharmacy open 14h30m", adjustments[1])
+	}
+	if adjustments, err := (Hours{}).DSTAdjustments("2022-03-20", storeData); err != nil || len(adjustments) != 0 {
+		t.Errorf("DSTAdjustments(<no change>) = %v, %v, want none", adjustments, err)
+	}
+
+	// ParseTimeSpan follows the policy too
+	start, _, err := (Hours{DST: DSTLatest}).ParseTimeSpan("1:30am-5:00am", "2022-11-06", 41.0428, -82.7258)
+	if err != nil || start.Format("3:04pm MST") != "1:30am EST" {
+		t.Errorf("Hours{DSTLatest}.ParseTimeSpan() start = %s, %v, want 1:30am EST", start, err)
+	}
+}
@@ -0,0 +1,201 @@
+package riteaid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Maximum number of response body bytes kept in APIError.Body
+const maxErrorBodyExcerpt = 512
+
+// APIError is returned when the RiteAid API responds with a non 2xx HTTP
+// status or with a Status other than "SUCCESS". It wraps ErrRiteAidAPIError
+// so existing checks keep working.
+//
+//	result, err := GetStoreData(address, 0.1)
+//	var apiErr *riteaid.APIError
+//	if errors.As(err, &apiErr) {
+//		fmt.Println(apiErr.StatusCode, apiErr.ErrCde, apiErr.ErrMsg)
+//	}
+//	if errors.Is(err, riteaid.ErrRiteAidAPIError) {
+//		// still true
+//	}
+type APIError struct {
+	// HTTP status code of the response
+	StatusCode int
+
+	// Fields copied from the decoded Result when available
+	Status    string
+	ErrCde    string
+	ErrMsg    string
+	ErrMsgDtl string
+
+	// Leading portion of the response body, useful when the API returns
+	// something other than JSON (i.e. an HTML maintenance page)
+	Body string
+
+	// Delay requested by the Retry-After response header, 0 when absent
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(ErrRiteAidAPIError.Error())
+	if e.StatusCode != 0 && (e.StatusCode < 200 || e.StatusCode > 299) {
+		fmt.Fprintf(&sb, ": HTTP %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	if e.ErrCde != "" {
+		fmt.Fprintf(&sb, ": [%s]", e.ErrCde)
+	}
+	if e.ErrMsg != "" {
+		fmt.Fprintf(&sb, ": %s", e.ErrMsg)
+	}
+	if e.ErrMsgDtl != "" {
+		fmt.Fprintf(&sb, " (%s)", e.ErrMsgDtl)
+	}
+	return sb.String()
+}
+
+// Unwrap allows errors.Is(err, ErrRiteAidAPIError) to match an *APIError.
+func (e *APIError) Unwrap() error {
+	return ErrRiteAidAPIError
+}
+
+// Retryable reports whether the same request may succeed if tried again.
+// Throttling (429) and server side failures (5xx) are retryable, any other
+// HTTP status or an API reported failure is permanent.
+func (e *APIError) Retryable() bool {
+	switch e.StatusCode {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Error wrapped by AmbiguousAddressError
+var ErrAmbiguousAddress = errors.New("address is ambiguous")
+
+// AmbiguousAddressError is returned along with the stores when the API could
+// not resolve an address to a single place. The stores returned are the ones
+// the API picked, around Result.Data.ResolvedAddress, which may not be the
+// place that was meant. Searching again with one of the candidates resolves
+// the ambiguity.
+//
+// This is not verified: the API has only been seen returning null for
+// Data.AmbiguousAddresses, reading it as a list of ResolvedAddress is a
+// guess. If that guess is wrong a search could return this error where
+// the address was not ambiguous.
+//
+//	result, err := GetStoreData("Springfield", 10)
+//	var ambiguous *riteaid.AmbiguousAddressError
+//	if errors.As(err, &ambiguous) {
+//		for _, candidate := range ambiguous.Candidates {
+//			fmt.Println(candidate.FormattedAddress)
+//		}
+//	}
+type AmbiguousAddressError struct {
+	Address    string            // The address searched for
+	Candidates []ResolvedAddress // Places the address may refer to
+}
+
+// Error lists the candidates.
+func (e *AmbiguousAddressError) Error() string {
+	names := make([]string, len(e.Candidates))
+	for i, candidate := range e.Candidates {
+		names[i] = candidate.FormattedAddress
+		if names[i] == "" {
+			names[i] = candidate.DisplayName
+		}
+	}
+	return fmt.Sprintf("%s: %q matches %d places: %s", ErrAmbiguousAddress, e.Address, len(e.Candidates), strings.Join(names, "; "))
+}
+
+// Unwrap allows errors.Is(err, ErrAmbiguousAddress) to match an
+// *AmbiguousAddressError.
+func (e *AmbiguousAddressError) Unwrap() error {
+	return ErrAmbiguousAddress
+}
+
+// Ambiguous reports whether the API matched the address to more than one
+// place. See AmbiguousAddressError.
+func (r Result) Ambiguous() bool {
+	return len(r.Data.AmbiguousAddresses) > 0
+}
+
+// IsRetryable classifies an error returned by a search as retryable
+// (transient) or permanent.
+//
+// Retryable errors are: an *APIError whose Retryable method returns true,
+// network timeouts, connection resets/refusals and truncated responses.
+// Context cancellation, radius validation and JSON decoding errors are
+// permanent.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// The caller gave up, never retry
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return false
+}
+
+// Private function to build an APIError from an unsuccessful HTTP response.
+func newHTTPError(resp *http.Response, body []byte) *APIError {
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Body:       bodyExcerpt(body),
+		RetryAfter: parseRetryAfter(resp.Header, time.Now()),
+	}
+}
+
+// Private function to build an APIError from a Result with a failed Status.
+func newResultError(statusCode int, result Result) *APIError {
+	return &APIError{
+		StatusCode: statusCode,
+		Status:     result.Status,
+		ErrCde:     result.ErrCde,
+		ErrMsg:     result.ErrMsg,
+		ErrMsgDtl:  result.ErrMsgDtl,
+	}
+}
+
+// Private function returning the leading portion of a response body.
+func bodyExcerpt(body []byte) string {
+	if len(body) > maxErrorBodyExcerpt {
+		body = body[:maxErrorBodyExcerpt]
+	}
+	return strings.TrimSpace(string(body))
+}
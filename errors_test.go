@@ -0,0 +1,113 @@
+package riteaid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+)
+
+func TestAPIErrorFromResult(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Status":"FAILURE","ErrCde":"E100","ErrMsg":"Invalid address","ErrMsgDtl":"geocode failed"}`))
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(WithBaseURL(srv.URL)).Search("nowhere", 1)
+	if !errors.Is(err, ErrRiteAidAPIError) {
+		t.Fatalf("Search() ERROR = %v, want %v", err, ErrRiteAidAPIError)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Search() ERROR = %T, want *APIError", err)
+	}
+	if apiErr.StatusCode != 200 || apiErr.Status != "FAILURE" || apiErr.ErrCde != "E100" || apiErr.ErrMsg != "Invalid address" || apiErr.ErrMsgDtl != "geocode failed" {
+		t.Errorf("Search() ERROR = %+v, want fields from the response", apiErr)
+	}
+	if apiErr.Retryable() || IsRetryable(err) {
+		t.Errorf("IsRetryable(%v) = true, want false", err)
+	}
+
+	want := "RiteAid API returned an error: [E100]: Invalid address (geocode failed)"
+	if apiErr.Error() != want {
+		t.Errorf("APIError.Error() = %q, want %q", apiErr.Error(), want)
+	}
+}
+
+func TestAPIErrorFromHTTPStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("<html><body>Down for maintenance</body></html>"))
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(NoRetry))
+	for name, search := range map[string]func() error{
+		"Search":     func() error { _, err := client.Search("Willard, OH", 1); return err },
+		"SearchJSON": func() error { _, err := client.SearchJSON("Willard, OH", 1); return err },
+	} {
+		err := search()
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("%s() ERROR = %v, want *APIError", name, err)
+		}
+		if apiErr.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("%s() StatusCode = %d, want %d", name, apiErr.StatusCode, http.StatusServiceUnavailable)
+		}
+		if apiErr.Body != "<html><body>Down for maintenance</body></html>" {
+			t.Errorf("%s() Body = %q, want the HTML page", name, apiErr.Body)
+		}
+		if !IsRetryable(err) {
+			t.Errorf("IsRetryable(%v) = false, want true", err)
+		}
+	}
+}
+
+func TestAmbiguousAddressError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Status":"SUCCESS","data":{"stores":[{"storeNumber":1}],"ambiguousAddresses":[
+			{"formattedAddress":"Springfield, IL","latitude":39.8,"longitude":-89.6},
+			{"displayName":"Springfield, OH","latitude":39.9,"longitude":-83.8}
+		]}}`))
+	}))
+	defer srv.Close()
+
+	result, err := NewClient(WithBaseURL(srv.URL)).Search("Springfield", 1)
+	if !errors.Is(err, ErrAmbiguousAddress) || errors.Is(err, ErrRiteAidAPIError) {
+		t.Fatalf("Search() ERROR = %v, want %v only", err, ErrAmbiguousAddress)
+	}
+	if !result.Ambiguous() || len(result.Data.Stores) != 1 {
+		t.Errorf("Search() = %+v, want the stores and Ambiguous()", result.Data)
+	}
+
+	want := `address is ambiguous: "Springfield" matches 2 places: Springfield, IL; Springfield, OH`
+	if err.Error() != want {
+		t.Errorf("AmbiguousAddressError.Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{ErrRadiusUnderMin, false},
+		{context.Canceled, false},
+		{fmt.Errorf("wrapped: %w", context.DeadlineExceeded), false},
+		{&APIError{StatusCode: http.StatusTooManyRequests}, true},
+		{&APIError{StatusCode: http.StatusBadGateway}, true},
+		{&APIError{StatusCode: http.StatusNotFound}, false},
+		{fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("IsRetryable(%v) = %t, want %t", tt.err, got, tt.want)
+		}
+	}
+}
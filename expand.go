@@ -0,0 +1,188 @@
+package riteaid
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+)
+
+const (
+	// Largest radius in miles the API searches
+	MaxRadius = 25
+
+	// Default radius in miles of the first search made by SearchAtLeast
+	DefaultExpandStartRadius = 0.5
+
+	// Default factor the radius grows by between SearchAtLeast searches
+	DefaultExpandGrowth = 2
+)
+
+// Error returned by SearchAtLeast when fewer stores than requested were found
+// after searching as far as allowed
+var ErrTooFewStores = errors.New("fewer stores found than requested")
+
+// ExpandOptions controls SearchAtLeast. The zero value is usable.
+type ExpandOptions struct {
+	// Radius of the first search. Defaults to DefaultExpandStartRadius.
+	StartRadius float64
+
+	// Factor the radius is multiplied by after each search, up to MaxRadius.
+	// Defaults to DefaultExpandGrowth.
+	Growth float64
+
+	// Number of rings of 25 mile searches, centered around the address, made
+	// once a MaxRadius search is not enough. 0 never goes past MaxRadius.
+	MaxRings int
+
+	// Options used for every search
+	Search SearchOptions
+}
+
+// ExpandResult is the outcome of SearchAtLeast.
+type ExpandResult struct {
+	// The merged result. Stores are unique, sorted nearest first, and their
+	// MilesFromCenter is measured from the resolved address.
+	Result Result
+
+	// Radius in miles around the address that was completely searched. When
+	// Rings is 0 this is the radius of the last search.
+	Radius float64
+
+	// Number of rings of offset searches made past MaxRadius
+	Rings int
+
+	// Number of searches made
+	Searches int
+}
+
+// SearchAtLeast runs Client.SearchAtLeast using DefaultClient.
+func SearchAtLeast(ctx context.Context, address string, n int, opts ExpandOptions) (ExpandResult, error) {
+	return DefaultClient.SearchAtLeast(ctx, address, n, opts)
+}
+
+// SearchAtLeast finds at least n stores around an address without having to
+// guess a radius. It starts with a small radius and grows it until n stores
+// are returned or MaxRadius is reached. After that, if opts.MaxRings allows
+// it, rings of MaxRadius searches are made around the address until enough
+// stores are found. The rings search "latitude,longitude" addresses, which
+// are not verified to be geocoded as such, see LatLng.String.
+//
+// When fewer than n stores are found the stores that were found are returned
+// along with ErrTooFewStores.
+//
+//	expanded, err := client.SearchAtLeast(ctx, "Willard, OH", 3, riteaid.ExpandOptions{MaxRings: 2})
+//	fmt.Printf("found %d stores within %.1f miles\n", len(expanded.Result.Data.Stores), expanded.Radius)
+func (c *Client) SearchAtLeast(ctx context.Context, address string, n int, opts ExpandOptions) (ExpandResult, error) {
+	radius := opts.StartRadius
+	if radius <= 0 {
+		radius = DefaultExpandStartRadius
+	}
+	growth := opts.Growth
+	if growth <= 1 {
+		growth = DefaultExpandGrowth
+	}
+
+	var expanded ExpandResult
+	for {
+		result, err := c.SearchWithOptions(ctx, address, math.Min(radius, MaxRadius), opts.Search)
+		if err != nil {
+			return expanded, err
+		}
+		expanded.Searches++
+		expanded.Result = result
+		expanded.Radius = math.Min(radius, MaxRadius)
+
+		if len(result.Data.Stores) >= n {
+			return expanded, nil
+		}
+		if radius >= MaxRadius {
+			break
+		}
+		radius *= growth
+	}
+
+	// Go past the API limit with rings of searches around the resolved address
+	center := expanded.Result.Data.ResolvedAddress.Location()
+	stores := make(map[uint32]Store)
+	for _, store := range expanded.Result.Data.Stores {
+		stores[store.StoreNumber] = store
+	}
+
+	// Stop the searches still running when returning early
+	ringCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for ring := 1; ring <= opts.MaxRings; ring++ {
+		var queries []Query
+		for _, p := range hexRing(center, MaxRadius, ring) {
+			queries = append(queries, Query{Address: p.String(), Radius: MaxRadius, Options: opts.Search})
+		}
+		for qr := range c.SearchMany(ringCtx, queries) {
+			if qr.Err != nil {
+				expanded.Result.Data.Stores = sortedByDistance(stores, center)
+				return expanded, qr.Err
+			}
+			expanded.Searches++
+			for _, store := range qr.Result.Data.Stores {
+				stores[store.StoreNumber] = store
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			expanded.Result.Data.Stores = sortedByDistance(stores, center)
+			return expanded, err
+		}
+
+		expanded.Rings = ring
+		expanded.Radius = math.Max(MaxRadius, (1.5*float64(ring)-1)*MaxRadius)
+		if len(stores) >= n {
+			break
+		}
+	}
+
+	if opts.MaxRings > 0 {
+		expanded.Result.Data.Stores = sortedByDistance(stores, center)
+	}
+	if len(expanded.Result.Data.Stores) < n {
+		return expanded, ErrTooFewStores
+	}
+	return expanded, nil
+}
+
+// Private function returning the centers of ring k of a hexagonal lattice of
+// circles of the given radius around center. Such circles cover the plane,
+// and rings 0 to k cover at least a disc of (1.5k-1)*radius.
+func hexRing(center LatLng, radius float64, k int) []LatLng {
+	// Axial directions of a pointy top hexagonal lattice
+	directions := [6][2]int{{1, 0}, {1, -1}, {0, -1}, {-1, 0}, {-1, 1}, {0, 1}}
+	spacing := radius * math.Sqrt(3)
+
+	q, r := directions[4][0]*k, directions[4][1]*k
+	points := make([]LatLng, 0, 6*k)
+	for side := 0; side < 6; side++ {
+		for step := 0; step < k; step++ {
+			east := spacing * (float64(q) + float64(r)/2)
+			north := spacing * float64(r) * math.Sqrt(3) / 2
+			points = append(points, center.Offset(north, east))
+			q, r = q+directions[side][0], r+directions[side][1]
+		}
+	}
+	return points
+}
+
+// Private function returning the stores sorted by their distance from
+// center, with MilesFromCenter recomputed.
+func sortedByDistance(stores map[uint32]Store, center LatLng) []Store {
+	sorted := make([]Store, 0, len(stores))
+	for _, store := range stores {
+		store.MilesFromCenter = DistanceMiles(center, store.Location())
+		sorted = append(sorted, store)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].MilesFromCenter == sorted[j].MilesFromCenter {
+			return sorted[i].StoreNumber < sorted[j].StoreNumber
+		}
+		return sorted[i].MilesFromCenter < sorted[j].MilesFromCenter
+	})
+	return sorted
+}
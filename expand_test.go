@@ -0,0 +1,96 @@
+package riteaid
+
+import (
+	"context"
+	"errors"
+	"math"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSearchAtLeast(t *testing.T) {
+	center := LatLng{41.0524, -82.7255}
+	stores := []Store{
+		{StoreNumber: 1, Latitude: center.Latitude, Longitude: center.Longitude},
+		{StoreNumber: 2, Latitude: center.Offset(3, 0).Latitude, Longitude: center.Longitude},
+		{StoreNumber: 3, Latitude: center.Offset(20, 0).Latitude, Longitude: center.Longitude},
+		{StoreNumber: 4, Latitude: center.Offset(-40, 0).Latitude, Longitude: center.Longitude},
+	}
+	srv, calls := newSweepServer(t, stores, 25, 0)
+	client := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(NoRetry))
+
+	// 0.5, 1, 2, 4 miles
+	expanded, err := client.SearchAtLeast(context.Background(), center.String(), 2, ExpandOptions{})
+	if err != nil || expanded.Radius != 4 || expanded.Searches != 4 || len(expanded.Result.Data.Stores) != 2 {
+		t.Errorf("SearchAtLeast(2) = radius %g, %d searches, %d stores, %v, want radius 4, 4 searches, 2 stores", expanded.Radius, expanded.Searches, len(expanded.Result.Data.Stores), err)
+	}
+
+	// Capped at 25 miles
+	expanded, err = client.SearchAtLeast(context.Background(), center.String(), 3, ExpandOptions{StartRadius: 5, Growth: 3})
+	if err != nil || expanded.Radius != 25 || expanded.Searches != 3 {
+		t.Errorf("SearchAtLeast(3) = radius %g, %d searches, %v, want radius 25 and 3 searches", expanded.Radius, expanded.Searches, err)
+	}
+
+	// Not enough without rings
+	expanded, err = client.SearchAtLeast(context.Background(), center.String(), 4, ExpandOptions{StartRadius: 25})
+	if !errors.Is(err, ErrTooFewStores) || len(expanded.Result.Data.Stores) != 3 {
+		t.Errorf("SearchAtLeast(4) = %d stores, %v, want 3 stores and %v", len(expanded.Result.Data.Stores), err, ErrTooFewStores)
+	}
+
+	// One ring of 6 searches finds the store 40 miles south
+	*calls = 0
+	expanded, err = client.SearchAtLeast(context.Background(), center.String(), 4, ExpandOptions{StartRadius: 25, MaxRings: 3})
+	if err != nil || expanded.Rings != 1 || expanded.Searches != 7 || *calls != 7 {
+		t.Fatalf("SearchAtLeast(4, <rings>) = %d rings, %d searches, %v, want 1 ring and 7 searches", expanded.Rings, expanded.Searches, err)
+	}
+	got := expanded.Result.Data.Stores
+	if len(got) != 4 || got[3].StoreNumber != 4 || math.Abs(got[3].MilesFromCenter-40) > 0.5 {
+		t.Errorf("SearchAtLeast(4, <rings>) stores = %+v, want store 4 last at ~40 miles", got)
+	}
+}
+
+func TestSearchAtLeastRingError(t *testing.T) {
+	center := LatLng{41.0524, -82.7255}
+	stores := []Store{{StoreNumber: 1, Latitude: center.Latitude, Longitude: center.Longitude}}
+
+	// The first search succeeds, every ring search fails
+	srv, _ := newSweepServer(t, stores, 25, 1)
+	client := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(NoRetry), WithConcurrency(2))
+	expanded, err := client.SearchAtLeast(context.Background(), center.String(), 2, ExpandOptions{StartRadius: 25, MaxRings: 1})
+	if err == nil || len(expanded.Result.Data.Stores) != 1 {
+		t.Fatalf("SearchAtLeast(<ring error>) = %d stores, %v, want 1 store and an error", len(expanded.Result.Data.Stores), err)
+	}
+
+	// The SearchMany workers and feeder of the ring all stop
+	var running int
+	for i := 0; i < 100; i++ {
+		buf := make([]byte, 1<<20)
+		running = strings.Count(string(buf[:runtime.Stack(buf, true)]), "(*Client).SearchMany")
+		if running == 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if running != 0 {
+		t.Errorf("SearchAtLeast(<ring error>) left %d SearchMany goroutines running", running)
+	}
+}
+
+func TestHexRing(t *testing.T) {
+	center := LatLng{41, -82}
+	for k := 1; k <= 3; k++ {
+		points := hexRing(center, 25, k)
+		if len(points) != 6*k {
+			t.Errorf("hexRing(%d) = %d points, want %d", k, len(points), 6*k)
+		}
+		for _, p := range points {
+			d := DistanceMiles(center, p)
+			if d < float64(k)*25*1.5-1 || d > float64(k)*25*math.Sqrt(3)+1 {
+				t.Errorf("hexRing(%d) point %s at %g miles, want between %g and %g", k, p, d, float64(k)*25*1.5, float64(k)*25*math.Sqrt(3))
+			}
+		}
+	}
+
+}
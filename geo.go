@@ -0,0 +1,174 @@
+package riteaid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Mean radius of the earth in miles
+const earthRadiusMiles = 3958.8
+
+// Approximate number of miles in one degree of latitude
+const milesPerDegreeLatitude = 69.0
+
+// LatLng is a geographic coordinate in decimal degrees.
+type LatLng struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// String returns the coordinate as "latitude,longitude", the form sent as
+// the address by SearchNear, the sweeps and SearchAtLeast's rings.
+//
+// That the RiteAid API geocoder accepts it as an address is an unverified
+// assumption: no response to such a search has been recorded. If it does
+// not, those searches are centered wherever the geocoder puts the string.
+//
+//	LatLng{41.0428, -82.7258}.String() -> "41.042800,-82.725800"
+func (p LatLng) String() string {
+	return fmt.Sprintf("%f,%f", p.Latitude, p.Longitude)
+}
+
+// DistanceMiles returns the great circle distance between a and b in miles.
+func DistanceMiles(a LatLng, b LatLng) float64 {
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLng := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMiles * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Offset returns the coordinate north and east miles away from p. Negative
+// values move south and west. Good enough for the short distances used
+// when searching, not for navigation.
+func (p LatLng) Offset(north float64, east float64) LatLng {
+	return LatLng{
+		Latitude:  p.Latitude + north/milesPerDegreeLatitude,
+		Longitude: p.Longitude + east/milesPerDegreeLongitude(p.Latitude),
+	}
+}
+
+// Private function returning the number of miles in one degree of
+// longitude at the given latitude.
+func milesPerDegreeLongitude(latitude float64) float64 {
+	miles := milesPerDegreeLatitude * math.Cos(latitude*math.Pi/180)
+	if miles < 0.01 {
+		// Avoid dividing by zero at the poles
+		miles = 0.01
+	}
+	return miles
+}
+
+// BoundingBox is a rectangular area between two corners.
+type BoundingBox struct {
+	SouthWest LatLng `json:"southWest"`
+	NorthEast LatLng `json:"northEast"`
+}
+
+// Contains reports whether p is inside the box, edges included.
+func (b BoundingBox) Contains(p LatLng) bool {
+	return p.Latitude >= b.SouthWest.Latitude && p.Latitude <= b.NorthEast.Latitude &&
+		p.Longitude >= b.SouthWest.Longitude && p.Longitude <= b.NorthEast.Longitude
+}
+
+// Center returns the middle of the box.
+func (b BoundingBox) Center() LatLng {
+	return LatLng{
+		Latitude:  (b.SouthWest.Latitude + b.NorthEast.Latitude) / 2,
+		Longitude: (b.SouthWest.Longitude + b.NorthEast.Longitude) / 2,
+	}
+}
+
+// Error returned when a coordinate is outside of the valid latitude and
+// longitude ranges
+var ErrInvalidCoordinate = errors.New("latitude must be between -90 and 90 and longitude between -180 and 180")
+
+// Valid reports whether p is a usable coordinate.
+func (p LatLng) Valid() bool {
+	return p.Latitude >= -90 && p.Latitude <= 90 && p.Longitude >= -180 && p.Longitude <= 180 &&
+		!math.IsNaN(p.Latitude) && !math.IsNaN(p.Longitude)
+}
+
+// Location returns the coordinate of the store.
+func (s Store) Location() LatLng {
+	return LatLng{Latitude: s.Latitude, Longitude: s.Longitude}
+}
+
+// Location returns the coordinate the API resolved the address to.
+func (r ResolvedAddress) Location() LatLng {
+	return LatLng{Latitude: r.Latitude, Longitude: r.Longitude}
+}
+
+// GetStoreDataNear finds the stores within radius miles of a coordinate
+// using DefaultClient. See Client.SearchNear.
+//
+//	searchResults, err := GetStoreDataNear(LatLng{Latitude: 41.0524, Longitude: -82.7255}, 5)
+func GetStoreDataNear(p LatLng, radius float64) (Result, error) {
+	return DefaultClient.SearchNear(context.Background(), p, radius)
+}
+
+// SearchNear finds the stores within radius miles of a coordinate, i.e. a
+// GPS position, instead of an address.
+//
+// The result is made consistent with the coordinate given rather than with
+// the API's geocoding of it: ResolvedAddress.Latitude/Longitude are set to
+// p, every MilesFromCenter is the distance from p, stores farther than
+// radius are dropped and the rest are sorted nearest first.
+//
+// The coordinate is sent as a "latitude,longitude" address, which is not
+// verified to be geocoded as such, see LatLng.String.
+func (c *Client) SearchNear(ctx context.Context, p LatLng, radius float64) (Result, error) {
+	return c.SearchNearWithOptions(ctx, p, radius, SearchOptions{})
+}
+
+// SearchNearWithOptions is SearchNear with the given SearchOptions. The
+// filters, including MaxResults, are applied after the stores are sorted
+// by their distance from p.
+func (c *Client) SearchNearWithOptions(ctx context.Context, p LatLng, radius float64, opts SearchOptions) (Result, error) {
+	if !p.Valid() {
+		return Result{}, ErrInvalidCoordinate
+	}
+
+	result, err := c.fetch(ctx, p.String(), radius, opts)
+	if err != nil {
+		return result, err
+	}
+	return opts.apply(nearResult(result, p, radius)), nil
+}
+
+// Private function recentering a result on p. The stores are copied as the
+// result may be shared with a Cache.
+func nearResult(result Result, p LatLng, radius float64) Result {
+	if radius > MaxRadius {
+		radius = MaxRadius
+	}
+
+	stores := make([]Store, 0, len(result.Data.Stores))
+	for _, store := range result.Data.Stores {
+		store.MilesFromCenter = DistanceMiles(p, store.Location())
+		if store.MilesFromCenter <= radius {
+			stores = append(stores, store)
+		}
+	}
+	sort.SliceStable(stores, func(i, j int) bool {
+		return stores[i].MilesFromCenter < stores[j].MilesFromCenter
+	})
+	result.Data.Stores = stores
+
+	resolved := &result.Data.ResolvedAddress
+	resolved.Latitude = p.Latitude
+	resolved.Longitude = p.Longitude
+	if resolved.FormattedAddress == "" {
+		resolved.FormattedAddress = p.String()
+	}
+	if resolved.DisplayName == "" {
+		resolved.DisplayName = resolved.FormattedAddress
+	}
+
+	return result
+}
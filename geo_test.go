@@ -0,0 +1,73 @@
+package riteaid
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDistanceMiles(t *testing.T) {
+	// Willard, OH to Norwalk, OH is about 14.3 miles as the crow flies
+	willard := LatLng{41.0524, -82.7255}
+	norwalk := LatLng{41.2420, -82.6157}
+	if got := DistanceMiles(willard, norwalk); math.Abs(got-14.3) > 0.2 {
+		t.Errorf("DistanceMiles(<willard>, <norwalk>) = %g, want ~14.3", got)
+	}
+	if got := DistanceMiles(willard, willard); got != 0 {
+		t.Errorf("DistanceMiles(<willard>, <willard>) = %g, want 0", got)
+	}
+
+	// Offset is the inverse of DistanceMiles for short distances
+	moved := willard.Offset(3, 4)
+	if got := DistanceMiles(willard, moved); math.Abs(got-5) > 0.05 {
+		t.Errorf("DistanceMiles(p, p.Offset(3, 4)) = %g, want ~5", got)
+	}
+}
+
+func TestSearchNear(t *testing.T) {
+	var gotAddress string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAddress = r.URL.Query().Get("address")
+
+		// The API geocodes the coordinate slightly off and returns one store outside of the radius
+		json.NewEncoder(w).Encode(Result{
+			Status: "SUCCESS",
+			Data: Data{
+				ResolvedAddress: ResolvedAddress{Latitude: 41.06, Longitude: -82.73},
+				Stores: []Store{
+					{StoreNumber: 1000, Latitude: 41.2420, Longitude: -82.6157, MilesFromCenter: 13},
+					{StoreNumber: 3357, Latitude: 41.0524, Longitude: -82.7255, MilesFromCenter: 1},
+					{StoreNumber: 9999, Latitude: 41.5, Longitude: -82.7255, MilesFromCenter: 9},
+				},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURL(srv.URL))
+	p := LatLng{41.0524, -82.7255}
+	result, err := client.SearchNear(context.Background(), p, 20)
+	if err != nil {
+		t.Fatalf("SearchNear() ERROR: %q", err)
+	}
+
+	if gotAddress != "41.052400,-82.725500" {
+		t.Errorf("SearchNear() address = %q, want %q", gotAddress, "41.052400,-82.725500")
+	}
+	if len(result.Data.Stores) != 2 || result.Data.Stores[0].StoreNumber != 3357 || result.Data.Stores[1].StoreNumber != 1000 {
+		t.Fatalf("SearchNear() stores = %v, want [3357 1000]", storeNumbers(result.Data.Stores))
+	}
+	if result.Data.Stores[0].MilesFromCenter != 0 || math.Abs(result.Data.Stores[1].MilesFromCenter-14.3) > 0.2 {
+		t.Errorf("SearchNear() MilesFromCenter = %g, %g, want 0, ~14.3", result.Data.Stores[0].MilesFromCenter, result.Data.Stores[1].MilesFromCenter)
+	}
+	if result.Data.ResolvedAddress.Location() != p || result.Data.ResolvedAddress.FormattedAddress != p.String() {
+		t.Errorf("SearchNear() ResolvedAddress = %+v, want centered on %s", result.Data.ResolvedAddress, p)
+	}
+
+	if _, err := client.SearchNear(context.Background(), LatLng{91, 0}, 1); err != ErrInvalidCoordinate {
+		t.Errorf("SearchNear(<invalid>) ERROR = %v, want %v", err, ErrInvalidCoordinate)
+	}
+}
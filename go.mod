@@ -1,5 +1,3 @@
 module github.com/zinthose/RiteAidStoreSearch
 
 go 1.18
-
-require github.com/zsefvlol/timezonemapper v1.0.0
@@ -0,0 +1,330 @@
+package riteaid
+
+import (
+	"errors"
+	"time"
+)
+
+// Hours computes store hours in the time zone chosen by its TimeZone
+// resolver. The package level functions such as GetStoreHours and
+// IsStoreOpen use the Hours of DefaultClient, a resolver can be chosen per
+// client with WithTimeZoneResolver or for a single call:
+//
+//	hours := riteaid.Hours{TimeZone: riteaid.StateTimeZone}
+//	storeOpen, rxOpen, err := hours.IsStoreOpen(time.Now(), storeData)
+type Hours struct {
+	// The resolver of the store location, DefaultTimeZoneResolver when nil
+	TimeZone TimeZoneResolver
+
+	// The instant of store hours skipped or repeated by a daylight saving
+	// time change
+	DST DSTPolicy
+
+	// The days searched by NextOpen and NextClose, DefaultSearchDays when 0
+	SearchDays int
+
+	// How long before closing GetOpenStatus reports StateClosingSoon,
+	// DefaultClosingSoon when 0
+	ClosingSoon time.Duration
+}
+
+// Hours returns the Hours of the client, using the TimeZoneResolver set by
+// WithTimeZoneResolver and the DSTPolicy set by WithDSTPolicy.
+func (c *Client) Hours() Hours {
+	return Hours{TimeZone: c.timeZone, DST: c.dst}
+}
+
+// Location returns the location the store's hours are kept in.
+func (h Hours) Location(storeData Store) (*time.Location, error) {
+	if h.TimeZone == nil {
+		return DefaultTimeZoneResolver.Location(storeData)
+	}
+	return h.TimeZone.Location(storeData)
+}
+
+// GetStoreHours is GetStoreHours in the location of the Hours.
+func (h Hours) GetStoreHours(date string, storeData Store) ([2]time.Time, [2]time.Time, error) {
+	loc, schedule, err := h.prepare(storeData)
+	if err != nil {
+		return [2]time.Time{}, [2]time.Time{}, err
+	}
+	return h.hoursOn(date, loc, storeData, schedule)
+}
+
+// GetStoreIntervals is GetStoreIntervals in the location of the Hours.
+func (h Hours) GetStoreIntervals(date string, storeData Store) ([][2]time.Time, [][2]time.Time, error) {
+	loc, schedule, err := h.prepare(storeData)
+	if err != nil {
+		return nil, nil, err
+	}
+	return h.intervalsOn(date, loc, storeData, schedule)
+}
+
+// GetStoreDayHours is GetStoreDayHours in the location of the Hours.
+func (h Hours) GetStoreDayHours(date string, storeData Store) (DayHours, DayHours, error) {
+	loc, schedule, err := h.prepare(storeData)
+	if err != nil {
+		return DayHours{}, DayHours{}, err
+	}
+	_, storeDay, err := dayHoursOn(date, DepartmentStore, loc, storeData, schedule)
+	if adjacentErr(err) != nil {
+		return DayHours{}, DayHours{}, err
+	}
+	_, rxDay, rxErr := dayHoursOn(date, DepartmentPharmacy, loc, storeData, schedule)
+	if adjacentErr(rxErr) != nil {
+		return DayHours{}, DayHours{}, rxErr
+	}
+	if err == nil {
+		err = rxErr
+	}
+	return storeDay, rxDay, err
+}
+
+// IsStoreOpen is IsStoreOpen in the location of the Hours.
+func (h Hours) IsStoreOpen(dateTime time.Time, storeData Store) (bool, bool, error) {
+	loc, schedule, err := h.prepare(storeData)
+	if err != nil {
+		return false, false, err
+	}
+	dateTime = dateTime.In(loc)
+
+	storeOpen, err := h.openOn(dateTime, DepartmentStore, loc, storeData, schedule)
+	if adjacentErr(err) != nil {
+		return false, false, err
+	}
+	rxOpen, rxErr := h.openOn(dateTime, DepartmentPharmacy, loc, storeData, schedule)
+	if adjacentErr(rxErr) != nil {
+		return false, false, rxErr
+	}
+	if err == nil {
+		err = rxErr
+	}
+	return storeOpen, rxOpen, err
+}
+
+// ParseTimeSpan is ParseTimeSpan under the DSTPolicy of the Hours. The
+// location is that of the coordinates, the TimeZone resolver is not used.
+func (h Hours) ParseTimeSpan(timeRange string, date string, latitude float64, longitude float64) (time.Time, time.Time, error) {
+	// Parse the time span into start and end times
+	hours, err := ParseDayHours(timeRange)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	// Get the time zone location of the store
+	loc, err := GetTZLocationLatLng(LatLng{Latitude: latitude, Longitude: longitude})
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	// Parse the date
+	day, err := time.ParseInLocation(DateFormat, date, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	// Return the start and end times
+	spans, err := h.spans(hours, day)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	span := outerSpan(spans)
+	return span[0], span[1], nil
+}
+
+// DSTAdjustments returns the opening intervals of the store on date whose
+// elapsed length differs from their length on the clock, because clocks
+// change during them. It is empty on most days.
+//
+//	adjustments, err := riteaid.Hours{}.DSTAdjustments("2022-03-13", storeData)
+//	for _, adjustment := range adjustments {
+//		fmt.Println(adjustment) // "store 12:00am-6:00am lasts 5h0m0s instead of 6h0m0s"
+//	}
+func (h Hours) DSTAdjustments(date string, storeData Store) ([]DSTAdjustment, error) {
+	loc, schedule, err := h.prepare(storeData)
+	if err != nil {
+		return nil, err
+	}
+
+	var adjustments []DSTAdjustment
+	for _, dept := range Departments {
+		day, hours, err := dayHoursOn(date, dept, loc, storeData, schedule)
+		if err != nil {
+			return nil, err
+		}
+		for _, interval := range hours.Intervals {
+			start, end, err := h.DST.interval(interval, day)
+			if err != nil {
+				return nil, err
+			}
+			wallClock := time.Duration(interval.End-interval.Start) * time.Minute
+			if elapsed := end.Sub(start); elapsed != wallClock {
+				adjustments = append(adjustments, DSTAdjustment{
+					Department: dept,
+					Interval:   interval,
+					Start:      start,
+					End:        end,
+					WallClock:  wallClock,
+					Elapsed:    elapsed,
+				})
+			}
+		}
+	}
+	return adjustments, nil
+}
+
+// Private method resolving the store location and parsing its regular
+// hours once. Days that cannot be parsed are kept in the schedule, they
+// only fail the dates that need them, see dayHoursOn.
+func (h Hours) prepare(storeData Store) (*time.Location, WeeklySchedule, error) {
+	loc, err := h.Location(storeData)
+	if err != nil {
+		return nil, WeeklySchedule{}, err
+	}
+	schedule, _ := NewWeeklySchedule(storeData)
+	return loc, schedule, nil
+}
+
+// Private method implementing GetStoreHours with the location resolved
+// and the regular hours already parsed.
+func (h Hours) hoursOn(date string, loc *time.Location, storeData Store, schedule WeeklySchedule) ([2]time.Time, [2]time.Time, error) {
+	storeSpans, rxSpans, err := h.intervalsOn(date, loc, storeData, schedule)
+	if adjacentErr(err) != nil {
+		return [2]time.Time{}, [2]time.Time{}, err
+	}
+	return outerSpan(storeSpans), outerSpan(rxSpans), err
+}
+
+// Private method implementing GetStoreIntervals with the location resolved
+// and the regular hours already parsed. When the hours of only one
+// department cannot be parsed the intervals of the other are still
+// returned, along with the ErrInvalidHours error.
+func (h Hours) intervalsOn(date string, loc *time.Location, storeData Store, schedule WeeklySchedule) ([][2]time.Time, [][2]time.Time, error) {
+	storeSpans, err := h.deptIntervalsOn(date, DepartmentStore, loc, storeData, schedule)
+	if adjacentErr(err) != nil {
+		return nil, nil, err
+	}
+	rxSpans, rxErr := h.deptIntervalsOn(date, DepartmentPharmacy, loc, storeData, schedule)
+	if adjacentErr(rxErr) != nil {
+		return nil, nil, rxErr
+	}
+	if err == nil {
+		err = rxErr
+	}
+	return storeSpans, rxSpans, err
+}
+
+// Private method returning the intervals of a department on date. Hours
+// that cannot be parsed have no intervals and are reported with an
+// ErrInvalidHours error, see adjacentErr.
+func (h Hours) deptIntervalsOn(date string, dept Department, loc *time.Location, storeData Store, schedule WeeklySchedule) ([][2]time.Time, error) {
+	day, hours, hoursErr := dayHoursOn(date, dept, loc, storeData, schedule)
+	if adjacentErr(hoursErr) != nil {
+		return nil, hoursErr
+	}
+	spans, err := h.spans(hours, day)
+	if err != nil {
+		return nil, err
+	}
+	return spans, hoursErr
+}
+
+// Private method implementing IsStoreOpen for one department, taking the
+// overnight hours of the previous day into account.
+func (h Hours) openOn(dateTime time.Time, dept Department, loc *time.Location, storeData Store, schedule WeeklySchedule) (bool, error) {
+	spans, err := h.deptIntervalsOn(dateTime.Format(DateFormat), dept, loc, storeData, schedule)
+	if err != nil {
+		return false, err
+	}
+
+	// Hours of the previous day may run past midnight
+	prevSpans, err := h.deptIntervalsOn(dateTime.AddDate(0, 0, -1).Format(DateFormat), dept, loc, storeData, schedule)
+	if err := adjacentErr(err); err != nil {
+		return false, err
+	}
+	return openAt(dateTime, append(spans, prevSpans...)...), nil
+}
+
+// Private method returning the start and end of each interval of hours on
+// day under the DSTPolicy.
+func (h Hours) spans(hours DayHours, day time.Time) ([][2]time.Time, error) {
+	var spans [][2]time.Time
+	for _, interval := range hours.Intervals {
+		start, end, err := h.DST.interval(interval, day)
+		if err != nil {
+			return nil, err
+		}
+		spans = append(spans, [2]time.Time{start, end})
+	}
+	return spans, nil
+}
+
+// Private function returning the first start and last end of spans, zero
+// times when there are none.
+func outerSpan(spans [][2]time.Time) [2]time.Time {
+	if len(spans) == 0 {
+		return [2]time.Time{}
+	}
+	return [2]time.Time{spans[0][0], spans[len(spans)-1][1]}
+}
+
+// Private function returning midnight of date in loc and the hours of a
+// department in effect that day, holiday hours first. Hours that cannot be
+// parsed are returned as HoursUnknown along with the ErrInvalidHours error.
+// Only the hours of dept are looked at, those of the other department do
+// not fail it.
+func dayHoursOn(date string, dept Department, loc *time.Location, storeData Store, schedule WeeklySchedule) (time.Time, DayHours, error) {
+	dt, err := time.ParseInLocation(DateFormat, date, loc)
+	if err != nil {
+		return time.Time{}, DayHours{}, err
+	}
+
+	// Return holiday hours for target date if any
+	for i := 0; i < len(storeData.HolidayHours); i++ {
+		holiday := storeData.HolidayHours[i]
+
+		// Verify holiday date is in the correct format
+		_, err := time.Parse(DateFormat, holiday.HolidayDate)
+		if err != nil {
+			return time.Time{}, DayHours{}, err
+		}
+
+		// Check if the holiday date matches the target date
+		if holiday.HolidayDate == date {
+			// Return the holiday hours
+			raw := holiday.StoreHours
+			if dept == DepartmentPharmacy {
+				raw = holiday.PharmacyHours
+			}
+			hours, err := ParseDayHours(raw)
+			hours.Source = SourceHoliday
+			return dt, hours, err
+		}
+	}
+
+	// Return standard hours for target date
+	weekday := dt.Weekday()
+	return dt, schedule.Day(weekday, dept), schedule.Err(weekday, dept)
+}
+
+// Private function returning err unless it only reports hours that cannot
+// be parsed. Those count as unknown on the days looked at around the one
+// asked about, i.e. for overnight hours, so only the dates that need them
+// fail.
+func adjacentErr(err error) error {
+	if errors.Is(err, ErrInvalidHours) {
+		return nil
+	}
+	return err
+}
+
+// Private function returning true if t falls within any of the spans,
+// start inclusive and end exclusive.
+func openAt(t time.Time, spans ...[2]time.Time) bool {
+	for _, span := range spans {
+		if !t.Before(span[0]) && t.Before(span[1]) {
+			return true
+		}
+	}
+	return false
+}
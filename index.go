@@ -0,0 +1,311 @@
+package riteaid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Error returned by LookupStore when a store number is not in the index,
+// even after a refresh
+var ErrStoreNotFound = errors.New("store not found")
+
+// Error returned by LookupStore when the Client has no StoreIndex
+var ErrNoStoreIndex = errors.New("client has no store index")
+
+// StoreIndex is a local index of stores by StoreNumber. It is filled by the
+// searches of a Client set up WithStoreIndex, by sweeps, by the entries of a
+// Cache, or from a file saved by an earlier run. It is safe for concurrent
+// use.
+type StoreIndex struct {
+	mu     sync.RWMutex
+	stores map[uint32]indexedStore
+}
+
+// Private type holding an indexed store and when it was last seen
+type indexedStore struct {
+	Store Store     `json:"store"`
+	Seen  time.Time `json:"seen"`
+}
+
+// NewStoreIndex returns an empty StoreIndex.
+func NewStoreIndex() *StoreIndex {
+	return &StoreIndex{stores: make(map[uint32]indexedStore)}
+}
+
+// LoadStoreIndex returns the StoreIndex saved to path by StoreIndex.Save. A
+// missing file returns an empty index so the same path can be used on the
+// first run.
+func LoadStoreIndex(path string) (*StoreIndex, error) {
+	index := NewStoreIndex()
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var stored []indexedStore
+	if err := unmarshalUnmapped(data, &stored); err != nil {
+		return nil, err
+	}
+	for _, entry := range stored {
+		index.stores[entry.Store.StoreNumber] = entry
+	}
+	return index, nil
+}
+
+// Save writes the index to path as JSON. The file is written to a temporary
+// name and renamed so a crash never leaves a partial index behind.
+func (x *StoreIndex) Save(path string) error {
+	x.mu.RLock()
+	stored := make([]indexedStore, 0, len(x.stores))
+	for _, entry := range x.stores {
+		stored = append(stored, entry)
+	}
+	x.mu.RUnlock()
+	sort.Slice(stored, func(i, j int) bool { return stored[i].Store.StoreNumber < stored[j].Store.StoreNumber })
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// Add indexes the stores, replacing any store with the same StoreNumber.
+func (x *StoreIndex) Add(stores ...Store) {
+	x.add(time.Now(), stores)
+}
+
+// AddResult indexes the stores of a search result.
+func (x *StoreIndex) AddResult(result Result) {
+	x.add(time.Now(), result.Data.Stores)
+}
+
+// AddCache indexes the stores of every entry of cache. Only MemoryCache,
+// DiskCache and other caches with a Range method like theirs can be listed,
+// for other caches this does nothing.
+//
+//	cache, _ := riteaid.NewDiskCache(dir)
+//	index := riteaid.NewStoreIndex()
+//	index.AddCache(cache)
+func (x *StoreIndex) AddCache(cache Cache) {
+	ranger, ok := cache.(interface {
+		Range(fn func(key string, entry CacheEntry) bool)
+	})
+	if !ok {
+		return
+	}
+	ranger.Range(func(key string, entry CacheEntry) bool {
+		x.add(entry.Stored, entry.Result.Data.Stores)
+		return true
+	})
+}
+
+// Private method indexing stores seen at the given time. A store already
+// seen more recently is kept.
+func (x *StoreIndex) add(seen time.Time, stores []Store) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	for _, store := range stores {
+		if entry, ok := x.stores[store.StoreNumber]; ok && entry.Seen.After(seen) {
+			continue
+		}
+		// MilesFromCenter only means something for the search that found it
+		store.MilesFromCenter = 0
+		x.stores[store.StoreNumber] = indexedStore{Store: store, Seen: seen}
+	}
+}
+
+// Get returns the indexed store with the given number.
+func (x *StoreIndex) Get(storeNumber uint32) (Store, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	entry, ok := x.stores[storeNumber]
+	return entry.Store, ok
+}
+
+// Stores returns every indexed store sorted by StoreNumber.
+func (x *StoreIndex) Stores() []Store {
+	x.mu.RLock()
+	stores := make([]Store, 0, len(x.stores))
+	for _, entry := range x.stores {
+		stores = append(stores, entry.Store)
+	}
+	x.mu.RUnlock()
+
+	sort.Slice(stores, func(i, j int) bool { return stores[i].StoreNumber < stores[j].StoreNumber })
+	return stores
+}
+
+// Len returns the number of indexed stores.
+func (x *StoreIndex) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.stores)
+}
+
+// RefreshStrategy controls what LookupStore does when a store number is not
+// in the index.
+type RefreshStrategy struct {
+	// Called to fill the index when a store number is unknown. Searches made
+	// with c are added to the index. nil never refreshes.
+	Refresh func(ctx context.Context, c *Client) error
+
+	// Minimum time between two refreshes, so lookups of numbers that do not
+	// exist do not sweep again and again. 0 refreshes on every miss.
+	MinInterval time.Duration
+}
+
+// RefreshStates returns a RefreshStrategy.Refresh that sweeps the given
+// states, see SweepState.
+//
+//	riteaid.WithStoreIndex(index, riteaid.RefreshStrategy{
+//		Refresh:     riteaid.RefreshStates([]string{"OH", "PA"}, riteaid.SweepOptions{}),
+//		MinInterval: 24 * time.Hour,
+//	})
+func RefreshStates(states []string, opts SweepOptions) func(ctx context.Context, c *Client) error {
+	return func(ctx context.Context, c *Client) error {
+		for _, state := range states {
+			if _, err := c.SweepState(ctx, state, opts); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// RefreshBox returns a RefreshStrategy.Refresh that sweeps box, see SweepBox.
+func RefreshBox(box BoundingBox, opts SweepOptions) func(ctx context.Context, c *Client) error {
+	return func(ctx context.Context, c *Client) error {
+		_, err := c.SweepBox(ctx, box, opts)
+		return err
+	}
+}
+
+// WithStoreIndex sets the index used by LookupStore. Every successful search
+// made by the Client, cached or not, adds its stores to the index.
+func WithStoreIndex(index *StoreIndex, strategy RefreshStrategy) Option {
+	return func(c *Client) {
+		c.index = index
+		c.indexStrategy = strategy
+	}
+}
+
+// LookupStore runs Client.LookupStore using DefaultClient.
+func LookupStore(ctx context.Context, storeNumber uint32) (Store, error) {
+	return DefaultClient.LookupStore(ctx, storeNumber)
+}
+
+// LookupStore returns the store with the given number from the Client's
+// StoreIndex. When the number is unknown the index is refreshed following
+// the RefreshStrategy and looked up again. ErrStoreNotFound is returned when
+// the store is still unknown.
+//
+//	index, _ := riteaid.LoadStoreIndex("stores.json")
+//	client := riteaid.NewClient(riteaid.WithStoreIndex(index, riteaid.RefreshStrategy{
+//		Refresh: riteaid.RefreshStates([]string{"OH"}, riteaid.SweepOptions{}),
+//	}))
+//	store, err := client.LookupStore(ctx, 3357)
+func (c *Client) LookupStore(ctx context.Context, storeNumber uint32) (Store, error) {
+	if c.index == nil {
+		return Store{}, ErrNoStoreIndex
+	}
+	if store, ok := c.index.Get(storeNumber); ok {
+		return store, nil
+	}
+
+	if err := c.refreshIndex(ctx); err != nil {
+		return Store{}, err
+	}
+	if store, ok := c.index.Get(storeNumber); ok {
+		return store, nil
+	}
+	return Store{}, ErrStoreNotFound
+}
+
+// Private type tracking the index refresh in progress. done is closed once
+// err is set.
+type indexRefresh struct {
+	done chan struct{}
+	err  error
+}
+
+// Private method running the index RefreshStrategy, unless it ran less than
+// MinInterval ago. Concurrent lookups share a single refresh: those waiting
+// for a refresh in progress do not run their own once it succeeds, and stop
+// waiting when their ctx is done.
+func (c *Client) refreshIndex(ctx context.Context) error {
+	strategy := c.indexStrategy
+	if strategy.Refresh == nil {
+		return nil
+	}
+
+	for {
+		c.indexMu.Lock()
+		if !c.indexRefreshed.IsZero() && time.Since(c.indexRefreshed) < strategy.MinInterval {
+			c.indexMu.Unlock()
+			return nil
+		}
+		running := c.indexRefresh
+		if running == nil {
+			break
+		}
+		c.indexMu.Unlock()
+
+		select {
+		case <-running.done:
+			if running.err == nil {
+				return nil
+			}
+			// The refresh failed, run another one unless ctx is done
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	// Still holding indexMu, this lookup runs the refresh
+	refresh := &indexRefresh{done: make(chan struct{})}
+	c.indexRefresh = refresh
+	c.indexMu.Unlock()
+
+	defer func() {
+		c.indexMu.Lock()
+		if refresh.err == nil {
+			c.indexRefreshed = time.Now()
+		}
+		c.indexRefresh = nil
+		c.indexMu.Unlock()
+		close(refresh.done)
+	}()
+	refresh.err = strategy.Refresh(ctx, c)
+	return refresh.err
+}
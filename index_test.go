@@ -0,0 +1,156 @@
+package riteaid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLookupStore(t *testing.T) {
+	stores := []Store{
+		{StoreNumber: 1, State: "OH", Latitude: 41.05, Longitude: -82.73},
+		{StoreNumber: 2, State: "OH", Latitude: 41.10, Longitude: -82.70},
+		{StoreNumber: 3, State: "OH", Latitude: 41.40, Longitude: -82.60},
+	}
+	srv, calls := newSweepServer(t, stores, 25, 0)
+
+	box := BoundingBox{SouthWest: LatLng{41.0, -83.0}, NorthEast: LatLng{41.5, -82.5}}
+	index := NewStoreIndex()
+	client := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(NoRetry), WithStoreIndex(index, RefreshStrategy{
+		Refresh:     RefreshBox(box, SweepOptions{Radius: 25}),
+		MinInterval: time.Hour,
+	}))
+
+	// Searches fill the index
+	if _, err := client.SearchContext(context.Background(), "41.05,-82.73", 5); err != nil {
+		t.Fatalf("SearchContext() ERROR: %q", err)
+	}
+	if store, err := client.LookupStore(context.Background(), 2); err != nil || store.StoreNumber != 2 || store.MilesFromCenter != 0 {
+		t.Errorf("LookupStore(2) = %+v, %v, want store 2", store, err)
+	}
+	if *calls != 1 {
+		t.Errorf("LookupStore(2) made %d calls, want none", *calls-1)
+	}
+
+	// An unknown number triggers the refresh sweep
+	if store, err := client.LookupStore(context.Background(), 3); err != nil || store.StoreNumber != 3 {
+		t.Errorf("LookupStore(3) = %+v, %v, want store 3", store, err)
+	}
+	if *calls < 2 || index.Len() != 3 {
+		t.Errorf("LookupStore(3) made %d calls and indexed %d stores, want a sweep indexing 3 stores", *calls-1, index.Len())
+	}
+
+	// A missing number does not sweep again within MinInterval
+	before := *calls
+	if _, err := client.LookupStore(context.Background(), 99); !errors.Is(err, ErrStoreNotFound) {
+		t.Errorf("LookupStore(99) ERROR = %v, want %v", err, ErrStoreNotFound)
+	}
+	if *calls != before {
+		t.Errorf("LookupStore(99) made %d calls, want none", *calls-before)
+	}
+
+	if _, err := NewClient().LookupStore(context.Background(), 1); !errors.Is(err, ErrNoStoreIndex) {
+		t.Errorf("LookupStore(<no index>) ERROR = %v, want %v", err, ErrNoStoreIndex)
+	}
+}
+
+func TestLookupStoreSharedRefresh(t *testing.T) {
+	var refreshes int32
+	release := make(chan struct{})
+	index := NewStoreIndex()
+	client := NewClient(WithStoreIndex(index, RefreshStrategy{
+		Refresh: func(ctx context.Context, c *Client) error {
+			atomic.AddInt32(&refreshes, 1)
+			<-release
+			return nil
+		},
+	}))
+
+	// Lookups of a missing number waiting for the same refresh
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.LookupStore(context.Background(), 99); !errors.Is(err, ErrStoreNotFound) {
+				t.Errorf("LookupStore(99) ERROR = %v, want %v", err, ErrStoreNotFound)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := atomic.LoadInt32(&refreshes); n != 1 {
+		t.Errorf("concurrent LookupStore(99) refreshed %d times, want 1", n)
+	}
+
+	// A later miss refreshes again with no MinInterval
+	if _, err := client.LookupStore(context.Background(), 99); !errors.Is(err, ErrStoreNotFound) || atomic.LoadInt32(&refreshes) != 2 {
+		t.Errorf("LookupStore(99) = %v after %d refreshes, want a second refresh", err, atomic.LoadInt32(&refreshes))
+	}
+}
+
+func TestLookupStoreWaitHonorsContext(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	client := NewClient(WithStoreIndex(NewStoreIndex(), RefreshStrategy{
+		Refresh: func(ctx context.Context, c *Client) error {
+			close(started)
+			<-release
+			return nil
+		},
+	}))
+	defer close(release)
+
+	// A slow refresh is running for another lookup
+	go client.LookupStore(context.Background(), 99)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	begin := time.Now()
+	if _, err := client.LookupStore(ctx, 99); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("LookupStore(<deadline>) ERROR = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if waited := time.Since(begin); waited > time.Second {
+		t.Errorf("LookupStore(<deadline>) waited %s for the other refresh", waited)
+	}
+}
+
+func TestStoreIndexPersistence(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewDiskCache(filepath.Join(dir, "cache"))
+	if err != nil {
+		t.Fatalf("NewDiskCache() ERROR: %q", err)
+	}
+	cache.Set("a|1", CacheEntry{Result: Result{Data: Data{Stores: []Store{{StoreNumber: 7, City: "OLD"}}}}, Stored: time.Now().Add(-time.Hour)})
+	cache.Set("b|1", CacheEntry{Result: Result{Data: Data{Stores: []Store{{StoreNumber: 7, City: "NEW"}, {StoreNumber: 8, Unmapped: map[string]json.RawMessage{"promo": json.RawMessage(`"Flu shots"`)}}}}}, Stored: time.Now()})
+
+	index := NewStoreIndex()
+	index.AddCache(cache)
+	if store, ok := index.Get(7); !ok || store.City != "NEW" || index.Len() != 2 {
+		t.Errorf("AddCache() store 7 = %+v, %d stores, want the most recent copy and 2 stores", store, index.Len())
+	}
+
+	path := filepath.Join(dir, "index.json")
+	if err := index.Save(path); err != nil {
+		t.Fatalf("Save() ERROR: %q", err)
+	}
+	loaded, err := LoadStoreIndex(path)
+	if err != nil || len(loaded.Stores()) != 2 || loaded.Stores()[0].City != "NEW" {
+		t.Errorf("LoadStoreIndex() = %+v, %v, want stores 7 and 8", loaded.Stores(), err)
+	}
+	if store, _ := loaded.Get(8); string(store.Unmapped["promo"]) != `"Flu shots"` {
+		t.Errorf("LoadStoreIndex() store 8 Unmapped = %s, want the promo kept", store.Unmapped)
+	}
+
+	empty, err := LoadStoreIndex(filepath.Join(dir, "missing.json"))
+	if err != nil || empty.Len() != 0 {
+		t.Errorf("LoadStoreIndex(<missing>) = %d stores, %v, want an empty index", empty.Len(), err)
+	}
+}
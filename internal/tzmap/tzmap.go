@@ -0,0 +1,305 @@
+// Package tzmap finds the IANA time zone of a coordinate from boundary data
+// embedded in the binary, so no files, network or third party modules are
+// needed at run time.
+//
+//	name := tzmap.Lookup(41.0428, -82.7258) // "America/New_York"
+//
+// The embedded zones.bin.gz is written by cmd/tzgen, see its documentation
+// to update it. The license of the data it was converted from is in NOTICE.
+package tzmap
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+)
+
+// Microdegrees per degree, coordinates are stored as whole microdegrees
+const Scale = 1e6
+
+// Size of the cells of the lookup grid in degrees
+const cellDegrees = 1
+
+// Leading bytes of the encoded data
+const magic = "TZMAP\x01"
+
+// Error returned when decoding data not written by Encode
+var ErrFormat = errors.New("tzmap: data is not in the tzmap format")
+
+//go:embed zones.bin.gz
+var embedded []byte
+
+// Point is a coordinate in microdegrees.
+type Point struct {
+	Lat int32
+	Lng int32
+}
+
+// NewPoint returns the Point of a coordinate in decimal degrees.
+func NewPoint(lat float64, lng float64) Point {
+	return Point{Lat: int32(math.Round(lat * Scale)), Lng: int32(math.Round(lng * Scale))}
+}
+
+// Box is a latitude and longitude range, Min inclusive and Max exclusive
+// except at the north pole and the antimeridian.
+type Box struct {
+	Min Point
+	Max Point
+}
+
+// Contains returns true if p is inside the box.
+func (b Box) Contains(p Point) bool {
+	return p.Lat >= b.Min.Lat && (p.Lat < b.Max.Lat || p.Lat == 90*Scale && b.Max.Lat == 90*Scale) &&
+		p.Lng >= b.Min.Lng && (p.Lng < b.Max.Lng || p.Lng == 180*Scale && b.Max.Lng == 180*Scale)
+}
+
+// Area is a part of a time zone. Without Rings it covers its whole Box,
+// otherwise the points of the Box inside the Rings by the even-odd rule, so
+// a ring inside another is a hole.
+type Area struct {
+	// Index of the zone in Map.Zones
+	Zone uint16
+
+	Box   Box
+	Rings [][]Point
+}
+
+// Contains returns true if p is inside the area.
+func (a *Area) Contains(p Point) bool {
+	if !a.Box.Contains(p) {
+		return false
+	}
+	if len(a.Rings) == 0 {
+		return true
+	}
+	inside := false
+	for _, ring := range a.Rings {
+		j := len(ring) - 1
+		for i := range ring {
+			yi, xi := float64(ring[i].Lat), float64(ring[i].Lng)
+			yj, xj := float64(ring[j].Lat), float64(ring[j].Lng)
+			if (yi > float64(p.Lat)) != (yj > float64(p.Lat)) && float64(p.Lng) < (xj-xi)*(float64(p.Lat)-yi)/(yj-yi)+xi {
+				inside = !inside
+			}
+			j = i
+		}
+	}
+	return inside
+}
+
+// Map is a list of time zone areas. The zone of a point is the zone of the
+// first area containing it.
+type Map struct {
+	// Where the boundaries come from, i.e. "timezone-boundary-builder 2022b"
+	Source string
+
+	// IANA zone names, i.e. "America/New_York"
+	Zones []string
+
+	Areas []Area
+
+	// Indexes of the areas overlapping each grid cell, in order
+	grid      [][]int32
+	indexOnce sync.Once
+}
+
+// Lookup returns the IANA name of the zone at a coordinate, or an empty
+// string when the coordinate is invalid or in no zone. The Areas must not
+// change once Lookup has been called.
+func (m *Map) Lookup(lat float64, lng float64) string {
+	if math.IsNaN(lat) || math.IsNaN(lng) || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return ""
+	}
+	m.indexOnce.Do(m.index)
+	p := NewPoint(lat, lng)
+	for _, i := range m.grid[cell(p)] {
+		if area := &m.Areas[i]; area.Contains(p) {
+			return m.Zones[area.Zone]
+		}
+	}
+	return ""
+}
+
+// Private method building the lookup grid.
+func (m *Map) index() {
+	m.grid = make([][]int32, rows*columns)
+	for i, area := range m.Areas {
+		minRow, minCol := cellOf(area.Box.Min)
+		maxRow, maxCol := cellOf(area.Box.Max)
+		for row := minRow; row <= maxRow; row++ {
+			for col := minCol; col <= maxCol; col++ {
+				m.grid[row*columns+col] = append(m.grid[row*columns+col], int32(i))
+			}
+		}
+	}
+}
+
+// Number of rows and columns of the lookup grid
+const (
+	rows    = 180 / cellDegrees
+	columns = 360 / cellDegrees
+)
+
+// Private function returning the row and column of the grid cell of p.
+func cellOf(p Point) (int, int) {
+	row := int((int64(p.Lat) + 90*Scale) / (cellDegrees * Scale))
+	col := int((int64(p.Lng) + 180*Scale) / (cellDegrees * Scale))
+	if row >= rows {
+		row = rows - 1
+	}
+	if col >= columns {
+		col = columns - 1
+	}
+	return row, col
+}
+
+// Private function returning the index of the grid cell of p.
+func cell(p Point) int {
+	row, col := cellOf(p)
+	return row*columns + col
+}
+
+// Private variables holding the embedded Map once decoded
+var (
+	defaultOnce sync.Once
+	defaultMap  *Map
+)
+
+// Default returns the Map embedded in the package. It is decoded on first
+// use.
+func Default() *Map {
+	defaultOnce.Do(func() {
+		m, err := Decode(bytes.NewReader(embedded))
+		if err != nil {
+			panic(fmt.Sprintf("tzmap: embedded data: %v", err))
+		}
+		defaultMap = m
+	})
+	return defaultMap
+}
+
+// Lookup returns the IANA name of the zone at a coordinate using the
+// embedded Map, or an empty string when the coordinate is in no zone.
+func Lookup(lat float64, lng float64) string {
+	return Default().Lookup(lat, lng)
+}
+
+// Encode writes the map in the gzip compressed format read by Decode.
+func (m *Map) Encode(w io.Writer) error {
+	if len(m.Zones) > math.MaxUint16 {
+		return fmt.Errorf("tzmap: %d zones, at most %d are supported", len(m.Zones), math.MaxUint16)
+	}
+	zw, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(zw)
+	put := func(v interface{}) {
+		if err == nil {
+			err = binary.Write(bw, binary.LittleEndian, v)
+		}
+	}
+	putString := func(s string) {
+		put(uint16(len(s)))
+		put([]byte(s))
+	}
+
+	put([]byte(magic))
+	putString(m.Source)
+	put(uint16(len(m.Zones)))
+	for _, zone := range m.Zones {
+		putString(zone)
+	}
+	put(uint32(len(m.Areas)))
+	for _, area := range m.Areas {
+		put(area.Zone)
+		put(area.Box)
+		put(uint32(len(area.Rings)))
+		for _, ring := range area.Rings {
+			put(uint32(len(ring)))
+			put(ring)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// Decode reads a Map written by Encode.
+func Decode(r io.Reader) (*Map, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	defer zr.Close()
+	br := bufio.NewReader(zr)
+	get := func(v interface{}) {
+		if err == nil {
+			err = binary.Read(br, binary.LittleEndian, v)
+		}
+	}
+	getString := func() string {
+		var n uint16
+		get(&n)
+		if err != nil {
+			return ""
+		}
+		b := make([]byte, n)
+		get(b)
+		return string(b)
+	}
+
+	head := make([]byte, len(magic))
+	get(head)
+	if err == nil && string(head) != magic {
+		return nil, ErrFormat
+	}
+
+	m := &Map{Source: getString()}
+	var zones uint16
+	get(&zones)
+	for i := 0; i < int(zones) && err == nil; i++ {
+		m.Zones = append(m.Zones, getString())
+	}
+	var areas uint32
+	get(&areas)
+	for i := 0; i < int(areas) && err == nil; i++ {
+		var area Area
+		var rings uint32
+		get(&area.Zone)
+		get(&area.Box)
+		get(&rings)
+		for j := 0; j < int(rings) && err == nil; j++ {
+			var points uint32
+			get(&points)
+			if err != nil {
+				break
+			}
+			ring := make([]Point, points)
+			get(ring)
+			area.Rings = append(area.Rings, ring)
+		}
+		if err == nil && int(area.Zone) >= len(m.Zones) {
+			err = fmt.Errorf("%w: area %d has zone %d of %d", ErrFormat, i, area.Zone, len(m.Zones))
+		}
+		m.Areas = append(m.Areas, area)
+	}
+	if err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("%w: truncated", ErrFormat)
+		}
+		return nil, err
+	}
+	return m, nil
+}
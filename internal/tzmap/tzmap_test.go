@@ -0,0 +1,76 @@
+package tzmap
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		lat, lng float64
+		want     string
+	}{
+		{41.0428, -82.7258, "America/New_York"},     // Willard, OH
+		{34.0522, -118.2437, "America/Los_Angeles"}, // Los Angeles, CA
+		{47.6062, -122.3321, "America/Los_Angeles"}, // Seattle, WA
+		{43.6150, -116.2023, "America/Boise"},       // Boise, ID
+		{33.4484, -112.0740, "America/Phoenix"},     // Phoenix, AZ
+		{42.3314, -83.0458, "America/Detroit"},      // Detroit, MI
+		{51.5074, -0.1278, "Europe/London"},
+		{-33.8688, 151.2093, "Australia/Sydney"},
+		{91, 0, ""},
+		{0, 181, ""},
+	}
+	for _, test := range tests {
+		if got := Lookup(test.lat, test.lng); got != test.want {
+			t.Errorf("Lookup(%g, %g) = %q, want %q", test.lat, test.lng, got, test.want)
+		}
+	}
+}
+
+func TestMap(t *testing.T) {
+	// A square zone with a square hole, over a zone covering everything
+	m := &Map{
+		Source: "test",
+		Zones:  []string{"Inner/Zone", "Outer/Zone"},
+		Areas: []Area{
+			{Zone: 0, Box: Box{NewPoint(10, 10), NewPoint(20, 20)}, Rings: [][]Point{
+				{NewPoint(10, 10), NewPoint(10, 20), NewPoint(20, 20), NewPoint(20, 10)},
+				{NewPoint(14, 14), NewPoint(14, 16), NewPoint(16, 16), NewPoint(16, 14)},
+			}},
+			{Zone: 1, Box: Box{NewPoint(-90, -180), NewPoint(90, 180)}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := m.Encode(&buf); err != nil {
+		t.Fatalf("Encode() ERROR: %q", err)
+	}
+	data := buf.Bytes()
+	decoded, err := Decode(bytes.NewReader(data))
+	if err != nil || decoded.Source != "test" || len(decoded.Areas) != 2 || len(decoded.Areas[0].Rings[1]) != 4 {
+		t.Fatalf("Decode() = %+v, %v, want the encoded map", decoded, err)
+	}
+
+	for _, test := range []struct {
+		lat, lng float64
+		want     string
+	}{
+		{12, 12, "Inner/Zone"},
+		{15, 15, "Outer/Zone"},
+		{25, 15, "Outer/Zone"},
+		{90, 180, "Outer/Zone"},
+	} {
+		if got := decoded.Lookup(test.lat, test.lng); got != test.want {
+			t.Errorf("Lookup(%g, %g) = %q, want %q", test.lat, test.lng, got, test.want)
+		}
+	}
+
+	if _, err := Decode(bytes.NewReader([]byte("not gzip"))); !errors.Is(err, ErrFormat) {
+		t.Errorf("Decode(<not gzip>) ERROR = %v, want %v", err, ErrFormat)
+	}
+	if _, err := Decode(bytes.NewReader(data[:len(data)/2])); !errors.Is(err, ErrFormat) {
+		t.Errorf("Decode(<truncated>) ERROR = %v, want %v", err, ErrFormat)
+	}
+}
@@ -0,0 +1,131 @@
+package riteaid
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Number of days after the day of t NextOpen and NextClose search by
+// default
+const DefaultSearchDays = 14
+
+// Error returned by NextOpen and NextClose when the department does not
+// open or close within the days searched
+var ErrNoHoursFound = errors.New("no opening or closing found within the days searched")
+
+// NextOpen returns the next time the department of the store is open, at or
+// after t, in the store's time zone. When the department is open at t, t is
+// returned. Regular hours, holiday hours, closed days and overnight hours
+// are all taken into account; days without hours data, or hours that
+// cannot be parsed, count as closed. Only hours of the day of t that cannot
+// be parsed return an error.
+// ErrNoHoursFound is returned when it does not open within
+// DefaultSearchDays.
+//
+//	opens, err := NextOpen(storeData, time.Now(), DepartmentPharmacy)
+//	if errors.Is(err, ErrNoHoursFound) {
+//		fmt.Println("The pharmacy is closed for the next two weeks")
+//	}
+//
+// This is a thin wrapper around DefaultClient.Hours().NextOpen.
+func NextOpen(storeData Store, t time.Time, dept Department) (time.Time, error) {
+	return DefaultClient.Hours().NextOpen(storeData, t, dept)
+}
+
+// NextClose returns the next time the department of the store closes after
+// t, in the store's time zone. When the department is closed at t, the end
+// of its next opening is returned. Intervals that meet, i.e. overnight
+// hours followed by hours starting at midnight, count as one opening.
+// ErrNoHoursFound is returned when it does not close within
+// DefaultSearchDays, i.e. a store open 24 hours every day.
+//
+//	closes, err := NextClose(storeData, time.Now(), DepartmentStore)
+//
+// This is a thin wrapper around DefaultClient.Hours().NextClose.
+func NextClose(storeData Store, t time.Time, dept Department) (time.Time, error) {
+	return DefaultClient.Hours().NextClose(storeData, t, dept)
+}
+
+// NextOpen is NextOpen in the location and under the DSTPolicy of the
+// Hours.
+func (h Hours) NextOpen(storeData Store, t time.Time, dept Department) (time.Time, error) {
+	openings, _, err := h.openings(storeData, t, dept)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, opening := range openings {
+		if !t.Before(opening[0]) && t.Before(opening[1]) {
+			return t.In(opening[0].Location()), nil
+		}
+		if opening[0].After(t) {
+			return opening[0], nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: %s of store %d does not open within %d days", ErrNoHoursFound, dept, storeData.StoreNumber, h.searchDays())
+}
+
+// NextClose is NextClose in the location and under the DSTPolicy of the
+// Hours.
+func (h Hours) NextClose(storeData Store, t time.Time, dept Department) (time.Time, error) {
+	openings, searched, err := h.openings(storeData, t, dept)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, opening := range openings {
+		// An opening running to the end of the days searched may go on
+		if opening[1].After(t) && opening[1].Before(searched) {
+			return opening[1], nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: %s of store %d does not close within %d days", ErrNoHoursFound, dept, storeData.StoreNumber, h.searchDays())
+}
+
+// Private method returning the days searched by NextOpen and NextClose.
+func (h Hours) searchDays() int {
+	if h.SearchDays <= 0 {
+		return DefaultSearchDays
+	}
+	return h.SearchDays
+}
+
+// Private method returning the openings of a department from the day before
+// t, for overnight hours, through the days searched after t's day, in order
+// and with the intervals that meet or overlap merged. The midnight ending
+// the last day searched is returned too.
+func (h Hours) openings(storeData Store, t time.Time, dept Department) ([][2]time.Time, time.Time, error) {
+	loc, schedule, err := h.prepare(storeData)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	t = t.In(loc)
+	days := h.searchDays()
+
+	var spans [][2]time.Time
+	for i := -1; i <= days; i++ {
+		daySpans, err := h.deptIntervalsOn(t.AddDate(0, 0, i).Format(DateFormat), dept, loc, storeData, schedule)
+		if i != 0 {
+			err = adjacentErr(err)
+		}
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		spans = append(spans, daySpans...)
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0].Before(spans[j][0]) })
+
+	var openings [][2]time.Time
+	for _, span := range spans {
+		if n := len(openings); n > 0 && !span[0].After(openings[n-1][1]) {
+			if span[1].After(openings[n-1][1]) {
+				openings[n-1][1] = span[1]
+			}
+			continue
+		}
+		openings = append(openings, span)
+	}
+
+	year, month, date := t.AddDate(0, 0, days+1).Date()
+	return openings, time.Date(year, month, date, 0, 0, 0, 0, loc), nil
+}
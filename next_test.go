@@ -0,0 +1,73 @@
+package riteaid
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNextOpenClose(t *testing.T) {
+	storeData := scheduleStore()
+	storeData.Latitude, storeData.Longitude = 41.0428, -82.7258
+	storeData.StoreHoursFriday = "7:00am-1:00am"
+	storeData.StoreHoursSaturday = "Closed"
+	storeData.RXHrsSun = ""
+	storeData.HolidayHours = []HolidayHours{{HolidayDate: "2022-05-30", StoreHours: "10:00am-6:00pm", PharmacyHours: "Closed"}}
+	loc, err := GetTZLocationLatLng(storeData.Location())
+	if err != nil {
+		t.Fatalf("GetTZLocationLatLng() ERROR: %q", err)
+	}
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2022, 5, day, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name  string
+		dept  Department
+		t     time.Time
+		open  time.Time
+		close time.Time
+	}{
+		// Sunday has no pharmacy hours and Memorial Day closes it
+		{"pharmacy after holiday", DepartmentPharmacy, at(29, 12, 0), at(31, 9, 0), at(31, 21, 0)},
+		{"overnight", DepartmentStore, at(27, 23, 0), at(27, 23, 0), at(28, 1, 0)},
+		{"after midnight", DepartmentStore, at(28, 0, 30), at(28, 0, 30), at(28, 1, 0)},
+		{"closed day", DepartmentStore, at(28, 12, 0), at(29, 8, 0), at(29, 22, 0)},
+		{"holiday store", DepartmentStore, at(30, 8, 0), at(30, 10, 0), at(30, 18, 0)},
+		{"at opening", DepartmentStore, at(26, 8, 0), at(26, 8, 0), at(26, 22, 0)},
+		{"at closing", DepartmentStore, at(26, 22, 0), at(27, 7, 0), at(28, 1, 0)},
+	}
+	for _, test := range tests {
+		open, err := NextOpen(storeData, test.t, test.dept)
+		if err != nil || !open.Equal(test.open) || open.Location().String() != loc.String() {
+			t.Errorf("%s: NextOpen(%s) = %s, %v, want %s", test.name, test.t, open, err, test.open)
+		}
+		closes, err := NextClose(storeData, test.t, test.dept)
+		if err != nil || !closes.Equal(test.close) {
+			t.Errorf("%s: NextClose(%s) = %s, %v, want %s", test.name, test.t, closes, err, test.close)
+		}
+	}
+
+	// Hours running into a day starting at midnight are one opening
+	storeData.StoreHoursSaturday = "12:00am-9:00pm"
+	if closes, err := NextClose(storeData, at(27, 23, 0), DepartmentStore); err != nil || !closes.Equal(at(28, 21, 0)) {
+		t.Errorf("NextClose(<overnight into Saturday>) = %s, %v, want 9pm Saturday", closes, err)
+	}
+
+	// A store that never closes, a pharmacy that never opens
+	for _, day := range []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday} {
+		*weeklyHours(&storeData, DepartmentStore)[day] = "Open 24 Hours"
+		*weeklyHours(&storeData, DepartmentPharmacy)[day] = "Closed"
+	}
+	storeData.HolidayHours = nil
+	hours := Hours{SearchDays: 3}
+	if _, err := hours.NextClose(storeData, at(27, 12, 0), DepartmentStore); !errors.Is(err, ErrNoHoursFound) {
+		t.Errorf("NextClose(<24/7>) ERROR = %v, want %v", err, ErrNoHoursFound)
+	}
+	if open, err := hours.NextOpen(storeData, at(27, 12, 0), DepartmentStore); err != nil || !open.Equal(at(27, 12, 0)) {
+		t.Errorf("NextOpen(<24/7>) = %s, %v, want now", open, err)
+	}
+	if _, err := hours.NextOpen(storeData, at(27, 12, 0), DepartmentPharmacy); !errors.Is(err, ErrNoHoursFound) {
+		t.Errorf("NextOpen(<always closed>) ERROR = %v, want %v", err, ErrNoHoursFound)
+	}
+}
@@ -0,0 +1,107 @@
+package riteaid
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// SearchOptions refines a store search. The zero value matches the behavior
+// of GetStoreData: all store types, a global ZIP code required, no filters.
+//
+// PharmacyOnly and GlobalZipCodeOptional are sent to the API, the other
+// fields filter the returned stores on the client.
+type SearchOptions struct {
+	// Only return stores with a pharmacy (pharmacyOnly=true)
+	PharmacyOnly bool
+
+	// Do not require the API to resolve a global ZIP code for the address
+	// (globalZipCodeRequired=false)
+	GlobalZipCodeOptional bool
+
+	// Only keep stores offering every one of these SpecialServicesKeys.
+	// Matching ignores case.
+	Services []string
+
+	// Only keep stores whose StoreType is one of these. Matching ignores case.
+	StoreTypes []string
+
+	// Keep at most this many stores, nearest first by MilesFromCenter. Stores
+	// at the same distance keep the order of the API. 0 means no limit and
+	// the order of the API.
+	MaxResults int
+}
+
+// GetStoreDataWithOptions is GetStoreData with the given SearchOptions
+// using DefaultClient.
+//
+//	// Pharmacy-only stores offering service PREF-100 within 10 miles
+//	searchResults, err := GetStoreDataWithOptions("Willard, OH", 10, SearchOptions{
+//		PharmacyOnly: true,
+//		Services:     []string{"PREF-100"},
+//	})
+func GetStoreDataWithOptions(address string, radius float64, opts SearchOptions) (Result, error) {
+	return DefaultClient.SearchWithOptions(context.Background(), address, radius, opts)
+}
+
+// Match reports whether a store passes the Services and StoreTypes filters.
+func (o SearchOptions) Match(store Store) bool {
+	if len(o.StoreTypes) > 0 && !containsFold(o.StoreTypes, store.StoreType) {
+		return false
+	}
+	for _, service := range o.Services {
+		if !containsFold(store.SpecialServicesKeys, service) {
+			return false
+		}
+	}
+	return true
+}
+
+// Private method returning a copy of result with the client side filters
+// applied. The stores are copied as the result may be shared with a Cache.
+func (o SearchOptions) apply(result Result) Result {
+	if len(o.Services) == 0 && len(o.StoreTypes) == 0 && o.MaxResults <= 0 {
+		return result
+	}
+
+	stores := make([]Store, 0, len(result.Data.Stores))
+	for _, store := range result.Data.Stores {
+		if o.Match(store) {
+			stores = append(stores, store)
+		}
+	}
+	if o.MaxResults > 0 {
+		sort.SliceStable(stores, func(i, j int) bool {
+			return stores[i].MilesFromCenter < stores[j].MilesFromCenter
+		})
+		if len(stores) > o.MaxResults {
+			stores = stores[:o.MaxResults]
+		}
+	}
+	result.Data.Stores = stores
+	return result
+}
+
+// Private method returning the cache key of a search. Only the options
+// sent to the API are part of the key, the filters are applied to the
+// cached result.
+func (o SearchOptions) cacheKey(address string, radius float64) string {
+	key := CacheKey(address, radius)
+	if o.PharmacyOnly {
+		key += "|pharmacyOnly"
+	}
+	if o.GlobalZipCodeOptional {
+		key += "|globalZipCodeOptional"
+	}
+	return key
+}
+
+// Private function reporting if list holds value, ignoring case.
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
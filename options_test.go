@@ -0,0 +1,65 @@
+package riteaid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSearchWithOptions(t *testing.T) {
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		json.NewEncoder(w).Encode(Result{
+			Status: "SUCCESS",
+			Data: Data{Stores: []Store{
+				{StoreNumber: 1, StoreType: "CORE", SpecialServicesKeys: []string{"PREF-100"}, MilesFromCenter: 0.5},
+				{StoreNumber: 2, StoreType: "CORE", SpecialServicesKeys: []string{"PREF-100", "PREF-600"}, MilesFromCenter: 3},
+				{StoreNumber: 3, StoreType: "EXPRESS", SpecialServicesKeys: []string{"PREF-100", "PREF-600"}, MilesFromCenter: 1},
+				{StoreNumber: 4, StoreType: "CORE", SpecialServicesKeys: []string{"pref-100", "pref-600"}, MilesFromCenter: 2},
+				{StoreNumber: 5, StoreType: "core", SpecialServicesKeys: []string{"PREF-100", "PREF-600"}, MilesFromCenter: 2},
+			}},
+		})
+	}))
+	defer srv.Close()
+
+	cache := NewMemoryCache(10)
+	client := NewClient(WithBaseURL(srv.URL), WithCache(cache, CachePolicy{TTL: time.Hour}))
+	opts := SearchOptions{
+		PharmacyOnly:          true,
+		GlobalZipCodeOptional: true,
+		Services:              []string{"PREF-100", "PREF-600"},
+		StoreTypes:            []string{"CORE"},
+		MaxResults:            2,
+	}
+
+	result, err := client.SearchWithOptions(context.Background(), "Willard, OH", 10, opts)
+	if err != nil || fmt.Sprint(storeNumbers(result.Data.Stores)) != "[4 5]" {
+		t.Errorf("SearchWithOptions() = %v, %v, want the nearest [4 5]", storeNumbers(result.Data.Stores), err)
+	}
+
+	want := "pharmacyOnly=true&globalZipCodeRequired=false&address=Willard%2C+OH&radius=10"
+	if len(queries) != 1 || queries[0] != want {
+		t.Errorf("SearchWithOptions() queries = %q, want [%q]", queries, want)
+	}
+
+	// The cached result is not affected by the filters
+	result, err = client.SearchWithOptions(context.Background(), "Willard, OH", 10, SearchOptions{PharmacyOnly: true, GlobalZipCodeOptional: true})
+	if err != nil || len(result.Data.Stores) != 5 || len(queries) != 1 {
+		t.Errorf("SearchWithOptions(<no filters>) = %v, %v with %d calls, want 5 cached stores", storeNumbers(result.Data.Stores), err, len(queries))
+	}
+
+	// Different API parameters are cached separately
+	if _, err := client.Search("Willard, OH", 10); err != nil || len(queries) != 2 {
+		t.Errorf("Search() = %v with %d calls, want 2 calls", err, len(queries))
+	}
+
+	client.InvalidateCache("Willard, OH", 10)
+	if cache.Len() != 0 {
+		t.Errorf("InvalidateCache() left %d entries", cache.Len())
+	}
+}
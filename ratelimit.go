@@ -0,0 +1,131 @@
+package riteaid
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter throttles the requests made by a Client. Wait blocks until a
+// request to host may be made or ctx is done. Implementations must be safe
+// for concurrent use.
+type Limiter interface {
+	Wait(ctx context.Context, host string) error
+}
+
+// WithRateLimiter sets the limiter consulted before every HTTP request made
+// by the client, including retries. Share the client (or the limiter) between
+// goroutines so all of them are held to the same rate.
+//
+//	// At most 2 requests per second with bursts of up to 5
+//	riteaid.DefaultClient = riteaid.NewClient(riteaid.WithRateLimiter(riteaid.NewRateLimiter(2, 5)))
+func WithRateLimiter(limiter Limiter) Option {
+	return func(c *Client) {
+		c.limiter = limiter
+	}
+}
+
+// RateLimiter is a token bucket Limiter shared by every host. The bucket
+// starts full, holds at most burst tokens and refills at rate tokens per
+// second. Each request consumes one token.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewRateLimiter returns a RateLimiter allowing rate requests per second
+// with bursts of up to burst requests. A rate of 0 or less disables the
+// limit. A burst under 1 is treated as 1.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// Wait blocks until a token is available or ctx is done. The host is ignored,
+// use HostRateLimiter to limit each host separately.
+func (l *RateLimiter) Wait(ctx context.Context, host string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if l.rate <= 0 {
+		return nil
+	}
+
+	// Reserve a token, going into debt if none are available
+	l.mu.Lock()
+	l.refill()
+	l.tokens--
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if err := sleepContext(ctx, wait); err != nil {
+		// Give the reservation back so other callers are not penalized
+		l.mu.Lock()
+		l.tokens++
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Private method adding the tokens earned since the last call.
+// Must be called with l.mu held.
+func (l *RateLimiter) refill() {
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+}
+
+// HostRateLimiter is a Limiter that keeps a separate token bucket per host,
+// each allowing rate requests per second with bursts of up to burst requests.
+type HostRateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   int
+	buckets map[string]*RateLimiter
+}
+
+// NewHostRateLimiter returns a HostRateLimiter. See NewRateLimiter for the
+// meaning of rate and burst.
+func NewHostRateLimiter(rate float64, burst int) *HostRateLimiter {
+	return &HostRateLimiter{
+		rate:    rate,
+		burst:   burst,
+		buckets: make(map[string]*RateLimiter),
+	}
+}
+
+// Wait blocks until a token for host is available or ctx is done.
+func (l *HostRateLimiter) Wait(ctx context.Context, host string) error {
+	l.mu.Lock()
+	bucket, ok := l.buckets[host]
+	if !ok {
+		bucket = NewRateLimiter(l.rate, l.burst)
+		l.buckets[host] = bucket
+	}
+	l.mu.Unlock()
+
+	return bucket.Wait(ctx, host)
+}
@@ -0,0 +1,82 @@
+package riteaid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBurst(t *testing.T) {
+	now := time.Date(2022, 5, 29, 12, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(1, 2)
+	limiter.now = func() time.Time { return now }
+
+	// The bucket starts full
+	for i := 0; i < 2; i++ {
+		if err := limiter.Wait(context.Background(), ""); err != nil {
+			t.Fatalf("Wait() #%d ERROR: %q", i, err)
+		}
+	}
+
+	// The third call must wait for a token which will not arrive before the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx, ""); err != context.DeadlineExceeded {
+		t.Errorf("Wait() ERROR = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// The canceled reservation was refunded, so one second later exactly one token is available
+	now = now.Add(time.Second)
+	if err := limiter.Wait(context.Background(), ""); err != nil {
+		t.Errorf("Wait() after refill ERROR: %q", err)
+	}
+	if limiter.tokens != 0 {
+		t.Errorf("tokens = %g, want 0", limiter.tokens)
+	}
+}
+
+func TestHostRateLimiter(t *testing.T) {
+	limiter := NewHostRateLimiter(0.001, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// Each host has its own bucket
+	if err := limiter.Wait(ctx, "a.example"); err != nil {
+		t.Errorf("Wait(a.example) ERROR: %q", err)
+	}
+	if err := limiter.Wait(ctx, "b.example"); err != nil {
+		t.Errorf("Wait(b.example) ERROR: %q", err)
+	}
+	if err := limiter.Wait(ctx, "a.example"); err == nil {
+		t.Errorf("Wait(a.example) ERROR = nil, want %v", context.DeadlineExceeded)
+	}
+}
+
+func TestClientRateLimited(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testStoreJSON))
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURL(srv.URL), WithRateLimiter(NewRateLimiter(50, 1)))
+
+	// 6 concurrent calls at 50/s with a burst of 1 take at least 100ms
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Search("Willard, OH", 1); err != nil {
+				t.Errorf("Search() ERROR: %q", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("6 rate limited searches took %s, want at least 100ms", elapsed)
+	}
+}
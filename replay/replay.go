@@ -0,0 +1,274 @@
+// Package replay provides an http.RoundTripper that records RiteAid API
+// exchanges to fixture files and replays them, so tests built on the
+// riteaid package can run offline and deterministically.
+//
+//	transport := replay.New(replay.ModeFromEnv("RITEAID_RECORD", replay.ModeReplay), "testdata/fixtures")
+//	client := riteaid.NewClient(riteaid.WithHTTPClient(&http.Client{Transport: transport}))
+//
+// Run the tests once with RITEAID_RECORD=record and network access to
+// capture the fixtures, then commit them. Hand written fixtures are named
+// with SyntheticSuffix so they can be told apart from recordings.
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Mode selects what a Transport does with a request.
+type Mode int
+
+const (
+	// ModeReplay serves every request from a fixture and fails when none
+	// was recorded. Nothing reaches the network.
+	ModeReplay Mode = iota
+
+	// ModeRecord sends every request to the network and (over)writes its
+	// fixture.
+	ModeRecord
+
+	// ModeAuto serves recorded fixtures and records the missing ones.
+	ModeAuto
+)
+
+// Suffix of hand written fixture files, i.e.
+// "4-walton-st-e-willard-oh-44890_r0.5.synthetic.json". They are served when
+// no recording exists for a request and are never written by recording.
+const SyntheticSuffix = ".synthetic.json"
+
+// Error returned in ModeReplay when no fixture matches a request
+var ErrNoFixture = errors.New("replay: no fixture recorded for request")
+
+// Response headers that change on every call and are never written to a
+// fixture
+var DefaultScrubHeaders = []string{
+	"Age",
+	"Cf-Ray",
+	"Date",
+	"Expires",
+	"Last-Modified",
+	"Report-To",
+	"Server-Timing",
+	"Set-Cookie",
+	"X-Request-Id",
+	"X-Akamai-Transformed",
+}
+
+// Transport is an http.RoundTripper recording and replaying getStores
+// exchanges. Requests are matched on their address and radius query
+// parameters only, so unrelated changes (headers, parameter order) do not
+// invalidate the fixtures.
+type Transport struct {
+	// What to do with requests, see Mode
+	Mode Mode
+
+	// Directory holding the fixture files
+	Dir string
+
+	// Transport used to reach the network when recording.
+	// Defaults to http.DefaultTransport.
+	Next http.RoundTripper
+
+	// Extra response headers to leave out of fixtures, in addition to
+	// DefaultScrubHeaders
+	ScrubHeaders []string
+
+	mu sync.Mutex
+}
+
+// New returns a Transport in the given mode storing fixtures in dir.
+func New(mode Mode, dir string) *Transport {
+	return &Transport{Mode: mode, Dir: dir}
+}
+
+// ModeFromEnv reads the mode from the environment variable name, ignoring
+// case and surrounding spaces:
+//
+//	"record", "1", "true"  ModeRecord
+//	"replay"               ModeReplay
+//	"auto"                 ModeAuto
+//
+// Any other value, including an unset or empty variable, returns fallback.
+func ModeFromEnv(name string, fallback Mode) Mode {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(name))) {
+	case "record", "1", "true":
+		return ModeRecord
+	case "replay":
+		return ModeReplay
+	case "auto":
+		return ModeAuto
+	}
+	return fallback
+}
+
+// Fixture is the content of a fixture file.
+type Fixture struct {
+	Request  FixtureRequest  `json:"request"`
+	Response FixtureResponse `json:"response"`
+}
+
+// FixtureRequest is the recorded request, kept for reference only.
+type FixtureRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+// FixtureResponse is the recorded response served on replay.
+type FixtureResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := filepath.Join(t.Dir, FixtureName(req))
+
+	if t.Mode != ModeRecord {
+		// Recordings take precedence over hand written fixtures
+		for _, name := range []string{path, filepath.Join(t.Dir, SyntheticName(req))} {
+			fixture, err := readFixture(name)
+			if err == nil {
+				return fixture.response(req), nil
+			}
+			if !os.IsNotExist(err) {
+				return nil, err
+			}
+		}
+		if t.Mode == ModeReplay {
+			return nil, fmt.Errorf("%w: %s %s (looked for %s)", ErrNoFixture, req.Method, req.URL, path)
+		}
+	}
+
+	return t.record(req, path)
+}
+
+// FixtureName returns the fixture file name used for a request, built from
+// its address and radius query parameters.
+//
+//	".../getStores?address=4+Walton+St+E%2C+Willard%2C+OH+44890&radius=0.5" -> "4-walton-st-e-willard-oh-44890_r0.5.json"
+func FixtureName(req *http.Request) string {
+	query := req.URL.Query()
+	address := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(query.Get("address")), "-"), "-")
+	if address == "" {
+		address = "no-address"
+	}
+
+	radius := query.Get("radius")
+	if r, err := strconv.ParseFloat(radius, 64); err == nil {
+		radius = strconv.FormatFloat(r, 'g', -1, 64)
+	}
+
+	return fmt.Sprintf("%s_r%s.json", address, radius)
+}
+
+// SyntheticName returns the name of the hand written fixture of a request,
+// FixtureName with SyntheticSuffix.
+func SyntheticName(req *http.Request) string {
+	return strings.TrimSuffix(FixtureName(req), ".json") + SyntheticSuffix
+}
+
+// Private regexp matching the characters not allowed in a fixture name
+var nonSlug = regexp.MustCompile(`[^a-z0-9.]+`)
+
+// Private method sending req to the network and saving the exchange.
+func (t *Transport) record(req *http.Request, path string) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	fixture := Fixture{
+		Request: FixtureRequest{Method: req.Method, URL: req.URL.String()},
+		Response: FixtureResponse{
+			StatusCode: resp.StatusCode,
+			Header:     t.scrub(resp.Header),
+			Body:       string(body),
+		},
+	}
+	if err := t.write(path, fixture); err != nil {
+		return nil, err
+	}
+
+	return fixture.response(req), nil
+}
+
+// Private method returning a copy of header without the volatile headers.
+func (t *Transport) scrub(header http.Header) http.Header {
+	clean := header.Clone()
+	for _, name := range DefaultScrubHeaders {
+		clean.Del(name)
+	}
+	for _, name := range t.ScrubHeaders {
+		clean.Del(name)
+	}
+	return clean
+}
+
+// Private method writing a fixture file.
+func (t *Transport) write(path string, fixture Fixture) error {
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Private function reading a fixture file.
+func readFixture(path string) (Fixture, error) {
+	var fixture Fixture
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fixture, err
+	}
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return fixture, fmt.Errorf("replay: %s: %w", path, err)
+	}
+	return fixture, nil
+}
+
+// Private method building the http.Response served for a fixture.
+func (f Fixture) response(req *http.Request) *http.Response {
+	header := f.Response.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Response.StatusCode, http.StatusText(f.Response.StatusCode)),
+		StatusCode:    f.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader([]byte(f.Response.Body))),
+		ContentLength: int64(len(f.Response.Body)),
+		Request:       req,
+	}
+}
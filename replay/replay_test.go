@@ -0,0 +1,129 @@
+package replay
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFixtureName(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://www.riteaid.com/services/ext/v2/stores/getStores?pharmacyOnly=false&globalZipCodeRequired=true&address=4+Walton+St+E%2C+Willard%2C+OH+44890&radius=0.500", nil)
+	want := "4-walton-st-e-willard-oh-44890_r0.5.json"
+	if got := FixtureName(req); got != want {
+		t.Errorf("FixtureName(<req>) = %q, want %q", got, want)
+	}
+	if got := SyntheticName(req); got != "4-walton-st-e-willard-oh-44890_r0.5.synthetic.json" {
+		t.Errorf("SyntheticName(<req>) = %q", got)
+	}
+}
+
+func TestSyntheticFixture(t *testing.T) {
+	dir := t.TempDir()
+	synthetic := `{"request":{},"response":{"statusCode":200,"body":"synthetic"}}`
+	if err := ioutil.WriteFile(filepath.Join(dir, "willard-oh_r1.synthetic.json"), []byte(synthetic), 0644); err != nil {
+		t.Fatalf("WriteFile() ERROR: %q", err)
+	}
+	client := &http.Client{Transport: New(ModeReplay, dir)}
+	get := func() string {
+		resp, err := client.Get("http://localhost/getStores?address=Willard%2C+OH&radius=1")
+		if err != nil {
+			t.Fatalf("Get() ERROR: %q", err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return string(body)
+	}
+	if got := get(); got != "synthetic" {
+		t.Errorf("Get(<synthetic only>) = %q, want the synthetic fixture", got)
+	}
+
+	// A recording of the same request wins
+	recorded := `{"request":{},"response":{"statusCode":200,"body":"recorded"}}`
+	if err := ioutil.WriteFile(filepath.Join(dir, "willard-oh_r1.json"), []byte(recorded), 0644); err != nil {
+		t.Fatalf("WriteFile() ERROR: %q", err)
+	}
+	if got := get(); got != "recorded" {
+		t.Errorf("Get(<recorded>) = %q, want the recording", got)
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"Status":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	url := srv.URL + "/getStores?address=Willard%2C+OH&radius=1"
+
+	// Record
+	client := &http.Client{Transport: New(ModeRecord, dir)}
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("Get(<record>) ERROR: %q", err)
+	}
+	resp.Body.Close()
+
+	fixture, err := readFixture(filepath.Join(dir, "willard-oh_r1.json"))
+	if err != nil {
+		t.Fatalf("readFixture() ERROR: %q", err)
+	}
+	if fixture.Response.Header.Get("Set-Cookie") != "" || fixture.Response.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("fixture header = %v, want Set-Cookie scrubbed and Content-Type kept", fixture.Response.Header)
+	}
+
+	// Replay, matching on address and radius only
+	client = &http.Client{Transport: New(ModeReplay, dir)}
+	resp, err = client.Get(srv.URL + "/getStores?radius=1.0&address=willard%2C+oh&pharmacyOnly=true")
+	if err != nil {
+		t.Fatalf("Get(<replay>) ERROR: %q", err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != `{"Status":"SUCCESS"}` || resp.StatusCode != 200 {
+		t.Errorf("Get(<replay>) = %d %q, want 200 and the recorded body", resp.StatusCode, body)
+	}
+	if calls != 1 {
+		t.Errorf("server called %d times, want 1", calls)
+	}
+
+	// Unknown requests fail in replay mode
+	_, err = client.Get(srv.URL + "/getStores?address=Norwalk%2C+OH&radius=1")
+	if !errors.Is(err, ErrNoFixture) {
+		t.Errorf("Get(<unknown>) ERROR = %v, want %v", err, ErrNoFixture)
+	}
+
+	// And are recorded in auto mode
+	client = &http.Client{Transport: New(ModeAuto, dir)}
+	resp, err = client.Get(srv.URL + "/getStores?address=Norwalk%2C+OH&radius=1")
+	if err != nil {
+		t.Fatalf("Get(<auto>) ERROR: %q", err)
+	}
+	resp.Body.Close()
+	if _, err := os.Stat(filepath.Join(dir, "norwalk-oh_r1.json")); err != nil || calls != 2 {
+		t.Errorf("Get(<auto>) did not record the fixture: %v, %d calls", err, calls)
+	}
+}
+
+func TestModeFromEnv(t *testing.T) {
+	t.Setenv("REPLAY_TEST_MODE", "record")
+	if got := ModeFromEnv("REPLAY_TEST_MODE", ModeReplay); got != ModeRecord {
+		t.Errorf("ModeFromEnv(record) = %d, want %d", got, ModeRecord)
+	}
+	t.Setenv("REPLAY_TEST_MODE", " TRUE ")
+	if got := ModeFromEnv("REPLAY_TEST_MODE", ModeReplay); got != ModeRecord {
+		t.Errorf("ModeFromEnv(true) = %d, want %d", got, ModeRecord)
+	}
+	t.Setenv("REPLAY_TEST_MODE", "")
+	if got := ModeFromEnv("REPLAY_TEST_MODE", ModeAuto); got != ModeAuto {
+		t.Errorf("ModeFromEnv(\"\") = %d, want %d", got, ModeAuto)
+	}
+}
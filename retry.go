@@ -0,0 +1,168 @@
+package riteaid
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how a Client retries a failed search. Only errors
+// classified as transient by RetryIf (IsRetryable by default) are retried,
+// and as every getStores call is a GET it is always safe to repeat.
+//
+// The delay before retry n (1 based) is
+//
+//	min(MaxBackoff, InitialBackoff * Multiplier^(n-1)) +/- Jitter
+//
+// unless the API sent a Retry-After header, which is honored instead
+// (still capped by MaxBackoff).
+type RetryPolicy struct {
+	// Total number of attempts including the first. 0 or 1 disables retries.
+	MaxAttempts int
+
+	// Delay before the first retry
+	InitialBackoff time.Duration
+
+	// Upper bound for any single delay. 0 means no upper bound.
+	MaxBackoff time.Duration
+
+	// Growth factor applied to the delay after every retry. Values under 1
+	// are treated as 1 (constant backoff).
+	Multiplier float64
+
+	// Fraction of the delay that is randomized, i.e. 0.2 = +/- 20%.
+	// Must be between 0 and 1.
+	Jitter float64
+
+	// Optional function deciding if an error should be retried.
+	// Defaults to IsRetryable.
+	RetryIf func(err error) bool
+
+	// Optional hook called before sleeping for a retry.
+	OnRetry func(event RetryEvent)
+}
+
+// RetryEvent describes a retry that is about to happen. It is passed to
+// RetryPolicy.OnRetry.
+type RetryEvent struct {
+	Attempt int           // The attempt that just failed (1 based)
+	Err     error         // The error returned by that attempt
+	Delay   time.Duration // How long the client will wait before the next attempt
+}
+
+// DefaultRetryPolicy is used by clients created without WithRetryPolicy,
+// including DefaultClient. It makes up to 3 attempts over roughly 1.5 seconds.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// NoRetry is a RetryPolicy that disables retries.
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// WithRetryPolicy sets the retry policy used for every search made by the
+// client.
+//
+//	client := riteaid.NewClient(riteaid.WithRetryPolicy(riteaid.RetryPolicy{
+//		MaxAttempts:    5,
+//		InitialBackoff: time.Second,
+//		MaxBackoff:     30 * time.Second,
+//		Multiplier:     2,
+//		Jitter:         0.3,
+//		OnRetry: func(e riteaid.RetryEvent) {
+//			log.Printf("attempt %d failed: %v, retrying in %s", e.Attempt, e.Err, e.Delay)
+//		},
+//	}))
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// Private method that reports if the failed attempt should be retried.
+func (p RetryPolicy) shouldRetry(attempt int, err error) bool {
+	if attempt >= p.MaxAttempts {
+		return false
+	}
+	if p.RetryIf != nil {
+		return p.RetryIf(err)
+	}
+	return IsRetryable(err)
+}
+
+// Private method returning the delay before the retry following attempt.
+func (p RetryPolicy) delay(attempt int, err error) time.Duration {
+	// The server knows best
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return p.cap(apiErr.RetryAfter)
+	}
+
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	d := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		d += d * jitter * (rand.Float64()*2 - 1)
+	}
+
+	return p.cap(time.Duration(d))
+}
+
+// Private method applying MaxBackoff to a delay.
+func (p RetryPolicy) cap(d time.Duration) time.Duration {
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// Private function that parses a Retry-After header which is either a
+// number of seconds or an HTTP date. Returns 0 when absent or invalid.
+func parseRetryAfter(header http.Header, now time.Time) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// Private function that waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,90 @@
+package riteaid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryTransientFailure(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(testStoreJSON))
+	}))
+	defer srv.Close()
+
+	var events []RetryEvent
+	client := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		Multiplier:     2,
+		OnRetry:        func(e RetryEvent) { events = append(events, e) },
+	}))
+
+	result, err := client.Search("Willard, OH", 1)
+	if err != nil || len(result.Data.Stores) != 1 {
+		t.Fatalf("Search() = %+v, %v, want 1 store", result.Data.Stores, err)
+	}
+	if calls != 3 {
+		t.Errorf("Search() made %d calls, want 3", calls)
+	}
+	if len(events) != 2 || events[0].Attempt != 1 || events[1].Attempt != 2 || events[1].Delay != 2*time.Millisecond {
+		t.Errorf("OnRetry events = %+v, want attempts 1 and 2", events)
+	}
+}
+
+func TestRetryPermanentFailure(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Millisecond}))
+	if _, err := client.Search("Willard, OH", 1); err == nil {
+		t.Errorf("Search() ERROR = nil, want *APIError")
+	}
+	if calls != 1 {
+		t.Errorf("Search() made %d calls, want 1", calls)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2022, 5, 29, 12, 0, 0, 0, time.UTC)
+
+	header := http.Header{}
+	header.Set("Retry-After", "7")
+	if got := parseRetryAfter(header, now); got != 7*time.Second {
+		t.Errorf("parseRetryAfter(%q) = %s, want 7s", "7", got)
+	}
+
+	header.Set("Retry-After", now.Add(time.Minute).Format(http.TimeFormat))
+	if got := parseRetryAfter(header, now); got != time.Minute {
+		t.Errorf("parseRetryAfter(<date>) = %s, want 1m0s", got)
+	}
+
+	// Retry-After wins over the backoff curve but is still capped
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 30 * time.Second}
+	if got := policy.delay(1, &APIError{StatusCode: 429, RetryAfter: 5 * time.Second}); got != 5*time.Second {
+		t.Errorf("delay(<Retry-After 5s>) = %s, want 5s", got)
+	}
+	if got := policy.delay(1, &APIError{StatusCode: 429, RetryAfter: time.Hour}); got != 30*time.Second {
+		t.Errorf("delay(<Retry-After 1h>) = %s, want 30s", got)
+	}
+}
+
+func TestRetryJitter(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, Multiplier: 2, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		if got := policy.delay(2, nil); got < 100*time.Millisecond || got > 300*time.Millisecond {
+			t.Fatalf("delay(2) = %s, want between 100ms and 300ms", got)
+		}
+	}
+}
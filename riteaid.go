@@ -1,20 +1,17 @@
 package riteaid
 
-// TODO: Adjust to a allow for channels and function caching
-
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
-	"log"
-	"net/http"
+	"io"
 	"net/url"
 	"regexp"
-	"strings"
 	"time"
+	_ "time/tzdata" // Time zones work without tzdata installed, i.e. in scratch containers
 
-	"github.com/zsefvlol/timezonemapper"
+	"github.com/zinthose/RiteAidStoreSearch/internal/tzmap"
 )
 
 const (
@@ -33,9 +30,10 @@ const (
 	DateTimeFormat_M = DateFormat + " " + TimeFormat_M
 
 	// Private constants
-	riteAidAPIURL  = "https://www.riteaid.com/services/ext/v2/stores/getStores?pharmacyOnly=false&globalZipCodeRequired=true&address=%s&radius=%.3g"
-	fedExPickupURL = `https://www.fedex.com/grd/rpp/ShowRPP.do?pickupType=Business&contactName=Onsite%%20Manager&state=%s&pickupLocation=0&weightOver150=No&companyName=%s&trackingId=%s&address1=%s&city=%s&zip=%s&phoneNum=%s&numPackages=%d`
-	googleMapURL   = "http://maps.google.com/maps?daddr=%s"
+	riteAidBaseURL  = "https://www.riteaid.com/services/ext/v2/stores/getStores"
+	riteAidAPIQuery = "?pharmacyOnly=%t&globalZipCodeRequired=%t&address=%s&radius=%.3g"
+	fedExPickupURL  = `https://www.fedex.com/grd/rpp/ShowRPP.do?pickupType=Business&contactName=Onsite%%20Manager&state=%s&pickupLocation=0&weightOver150=No&companyName=%s&trackingId=%s&address1=%s&city=%s&zip=%s&phoneNum=%s&numPackages=%d`
+	googleMapURL    = "http://maps.google.com/maps?daddr=%s"
 )
 
 // Error returned when the radius is under the minimum value
@@ -46,6 +44,9 @@ var ErrRadiusOverMax = errors.New("radius is greater than 25 the API will only r
 
 // Error returned when a parsed time span is out of expected order.
 //  Expected order is: "8:00am-5:00pm" || "8:00 AM-5:00 PM"
+//
+// Deprecated: spans ending before their start, i.e. "7:00am-1:00am", are
+// now parsed as overnight hours and this error is no longer returned.
 var ErrTimeParseOrder = errors.New("parsed start time is after parsed end time")
 
 // Error returned when RiteAid API returns an error
@@ -55,29 +56,54 @@ var ErrRiteAidAPIError = errors.New("RiteAid API returned an error")
 // As of 5/29/2022 the structure is accurate but is subject to change as the API evolves.
 //
 // Known issues:
-//  - The API returns are not fully mapped and some are being omitted from the struct
-//  - Warnings is not verified
-//  - AmbiguousAddresses is being omitted from import
+//  - The API returns are not fully mapped, fields missing from the structs are
+//    kept in their Unmapped field when a Result is decoded
+//  - Warnings is not verified, see Warning
+//  - AmbiguousAddresses is not verified, the API has only been seen
+//    returning null, its shape is a guess
 type Result struct {
 	Data      Data   `json:"data,omitempty"`
 	Status    string `json:"Status"`
 	ErrCde    string `json:"ErrCde,omitempty"`
 	ErrMsg    string `json:"ErrMsg,omitempty"`
 	ErrMsgDtl string `json:"ErrMsgDtl,omitempty"`
+
+	// Fields returned by the API that are not mapped above, as raw JSON
+	Unmapped map[string]json.RawMessage `json:"-"`
 }
 
 type Data struct {
-	Stores          []Store         `json:"stores"`
-	GlobalZipCode   string          `json:"globalZipCode,omitempty"`
-	ResolvedAddress ResolvedAddress `json:"resolvedAddress"`
-	Warnings        []string        `json:"warnings"` // TODO: This is a guess and needs verified
-	// AmbiguousAddresses []AmbiguousAddress `json:"ambiguousAddresses"` // TODO: What is this?
+	Stores             []Store           `json:"stores"`
+	GlobalZipCode      string            `json:"globalZipCode,omitempty"`
+	ResolvedAddress    ResolvedAddress   `json:"resolvedAddress"`
+	Warnings           []Warning         `json:"warnings"`
+	AmbiguousAddresses []ResolvedAddress `json:"ambiguousAddresses"` // Candidates when the address matched more than one place, unverified
+
+	// Fields returned by the API that are not mapped above, as raw JSON
+	Unmapped map[string]json.RawMessage `json:"-"`
 }
 
 type HolidayHours struct {
 	HolidayDate   string `json:"holidayDate,omitempty"`
 	StoreHours    string `json:"storeHours"`
 	PharmacyHours string `json:"pharmacyHours"`
+
+	// Fields returned by the API that are not mapped above, as raw JSON
+	Unmapped map[string]json.RawMessage `json:"-"`
+}
+
+// Warnings are returned as plain strings or as objects with a code and a
+// message. Both are decoded into a Warning, a plain string becomes the
+// Message.
+//
+// This is not verified: the API has only been seen returning null warnings,
+// the forms above are a guess. Anything else is kept in Unmapped.
+type Warning struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+
+	// Fields returned by the API that are not mapped above, as raw JSON
+	Unmapped map[string]json.RawMessage `json:"-"`
 }
 
 // SpecialHours is mapped as the key names are dynamic and not known ahead of time
@@ -87,6 +113,9 @@ type PickupDateAndTimes struct {
 	DefaultTime  string            `json:"defaultTime"`
 	Earliest     string            `json:"earliest"`
 	SpecialHours map[string]string `json:"specialHours,omitempty"` // SpecialHours is returned with multiple values. Ex. {"2022-05-28": "1:00 PM-5:00 PM"}
+
+	// Fields returned by the API that are not mapped above, as raw JSON
+	Unmapped map[string]json.RawMessage `json:"-"`
 }
 
 type ResolvedAddress struct {
@@ -116,42 +145,49 @@ type ResolvedAddress struct {
 	Longitude  float64 `json:"longitude"`
 	PostalCode string  `json:"postalCode,omitempty"`
 	PostalTown string  `json:"postalTown,omitempty"`
+
+	// Fields returned by the API that are not mapped above, as raw JSON
+	Unmapped map[string]json.RawMessage `json:"-"`
 }
 
-// Event is not imported as the format is now known as of 2022/05/29
+// Store is a single store location. Event is kept as raw JSON as its format
+// is not known: the API has only been seen returning null.
 type Store struct {
-	StoreNumber         uint32   `json:"storeNumber"`
-	Address             string   `json:"address"`
-	City                string   `json:"city"`
-	State               string   `json:"state"`
-	Zipcode             string   `json:"zipcode"`
-	TimeZone            string   `json:"timeZone"`
-	FullZipCode         string   `json:"fullZipCode"`
-	FullPhone           string   `json:"fullPhone"`
-	LocationDescription string   `json:"locationDescription"`
-	StoreHoursMonday    string   `json:"storeHoursMonday"`
-	StoreHoursTuesday   string   `json:"storeHoursTuesday"`
-	StoreHoursWednesday string   `json:"storeHoursWednesday"`
-	StoreHoursThursday  string   `json:"storeHoursThursday"`
-	StoreHoursFriday    string   `json:"storeHoursFriday"`
-	StoreHoursSaturday  string   `json:"storeHoursSaturday"`
-	StoreHoursSunday    string   `json:"storeHoursSunday"`
-	RXHrsMon            string   `json:"rxHrsMon"`
-	RXHrsTue            string   `json:"rxHrsTue"`
-	RXHrsWed            string   `json:"rxHrsWed"`
-	RXHrsThu            string   `json:"rxHrsThu"`
-	RXHrsFri            string   `json:"rxHrsFri"`
-	RXHrsSat            string   `json:"rxHrsSat"`
-	RXHrsSun            string   `json:"rxHrsSun"`
-	StoreType           string   `json:"storeType"`
-	Latitude            float64  `json:"latitude"`
-	Longitude           float64  `json:"longitude"`
-	Name                string   `json:"name"`
-	MilesFromCenter     float64  `json:"milesFromCenter"`
-	SpecialServicesKeys []string `json:"specialServicesKeys,omitempty"`
-	// Event string `json:"event"` // TODO - Not sure what this is.
-	HolidayHours       []HolidayHours     `json:"holidayHours,omitempty"`
-	PickupDateAndTimes PickupDateAndTimes `json:"pickupDateAndTimes"`
+	StoreNumber         uint32             `json:"storeNumber"`
+	Address             string             `json:"address"`
+	City                string             `json:"city"`
+	State               string             `json:"state"`
+	Zipcode             string             `json:"zipcode"`
+	TimeZone            string             `json:"timeZone"`
+	FullZipCode         string             `json:"fullZipCode"`
+	FullPhone           string             `json:"fullPhone"`
+	LocationDescription string             `json:"locationDescription"`
+	StoreHoursMonday    string             `json:"storeHoursMonday"`
+	StoreHoursTuesday   string             `json:"storeHoursTuesday"`
+	StoreHoursWednesday string             `json:"storeHoursWednesday"`
+	StoreHoursThursday  string             `json:"storeHoursThursday"`
+	StoreHoursFriday    string             `json:"storeHoursFriday"`
+	StoreHoursSaturday  string             `json:"storeHoursSaturday"`
+	StoreHoursSunday    string             `json:"storeHoursSunday"`
+	RXHrsMon            string             `json:"rxHrsMon"`
+	RXHrsTue            string             `json:"rxHrsTue"`
+	RXHrsWed            string             `json:"rxHrsWed"`
+	RXHrsThu            string             `json:"rxHrsThu"`
+	RXHrsFri            string             `json:"rxHrsFri"`
+	RXHrsSat            string             `json:"rxHrsSat"`
+	RXHrsSun            string             `json:"rxHrsSun"`
+	StoreType           string             `json:"storeType"`
+	Latitude            float64            `json:"latitude"`
+	Longitude           float64            `json:"longitude"`
+	Name                string             `json:"name"`
+	MilesFromCenter     float64            `json:"milesFromCenter"`
+	SpecialServicesKeys []string           `json:"specialServicesKeys,omitempty"`
+	Event               json.RawMessage    `json:"event"`
+	HolidayHours        []HolidayHours     `json:"holidayHours,omitempty"`
+	PickupDateAndTimes  PickupDateAndTimes `json:"pickupDateAndTimes"`
+
+	// Fields returned by the API that are not mapped above, as raw JSON
+	Unmapped map[string]json.RawMessage `json:"-"`
 }
 
 // Function will place call to RiteAid API and return the store location data.
@@ -165,27 +201,26 @@ type Store struct {
 // (required - must be between 0.01 and 25)
 //
 // RETURNS: The raw store location data. This is the string value returned
-// from the API call in JSON format
+// from the API call in JSON format. A non 2xx HTTP status is returned as
+// an *APIError
+//
+// This is a thin wrapper around DefaultClient.SearchJSONContext.
 func GetStoreDataJSON(address string, radius float64) (string, error) {
-	url, err := __getStoreDataURL(address, radius)
-	if err != nil && err != ErrRadiusOverMax {
-		return "", err
-	}
-
-	// Make the request
-	resp, err := http.Get(url)
-	if err != nil {
-		return "", err
-	}
-
-	// Ready the response
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
-	}
-	sb := string(body)
+	return DefaultClient.SearchJSONContext(context.Background(), address, radius)
+}
 
-	return sb, nil
+// Function will decode store location data previously retrieved from the
+// API, i.e. saved by GetStoreDataJSON, from a reader.
+//
+// RETURNS: The store location data as a struct. In addition, if the data
+// holds a failed API call, an *APIError wrapping ErrRiteAidAPIError will be
+// raised along with the decoded struct
+//
+//  file, _ := os.Open("stores.json")
+//  defer file.Close()
+//  searchResults, err := ParseResult(file)
+func ParseResult(r io.Reader) (Result, error) {
+	return parseResult(r, 0)
 }
 
 // Function will place call to API and return the store location data
@@ -200,89 +235,51 @@ func GetStoreDataJSON(address string, radius float64) (string, error) {
 // (required - must be between 0.01 and 25)
 //
 // RETURNS: The store location data as a struct. In addition,
-// if the API call fails, a ErrRiteAidAPIError will be raised
+// if the API call fails, an *APIError wrapping ErrRiteAidAPIError will be
+// raised carrying the ErrCde, ErrMsg and ErrMsgDtl of the response. When the
+// address matched more than one place the stores are still returned along
+// with an *AmbiguousAddressError listing the candidates
+//
+// This is a thin wrapper around DefaultClient.SearchContext.
 func GetStoreData(address string, radius float64) (Result, error) {
-	var result Result
-	var err error
-
-	// Get Store Data
-	sb, err := GetStoreDataJSON(address, radius)
-	if err != nil {
-		return result, err
-	}
-
-	// Unmarshal the response
-	err = json.Unmarshal([]byte(sb), &result)
-	if err != nil {
-		return result, err
-	}
-
-	// If the API call failed, return the error
-	if result.Status != "SUCCESS" {
-		log.Println("RiteAid API call failed: " + result.ErrMsg)
-		return result, ErrRiteAidAPIError
-	}
-
-	// Return the result
-	return result, nil
+	return DefaultClient.SearchContext(context.Background(), address, radius)
 }
 
 // ParseTimeSpan takes a time range string and parses it into a start and end time.
 // The time zone is required to ensure proper calculations based on the locality of
 // the user, the store, and the server.
-//  timeRange i.e. "8:00am-5:00pm" || "8:00 AM-5:00 PM"
+//  timeRange i.e. "8:00am-5:00pm" || "8:00 AM-5:00 PM" || "Closed" || "Open 24 Hours"
 //       date i.e. "2006-01-02"
-//   timeZone i.e. "MST"
+//   latitude i.e. 41.0428
+//  longitude i.e. -82.7258
+//
+// A closed day or an empty timeRange returns zero times, a 24 hour day
+// returns midnight to the following midnight. An overnight span such as
+// "7:00am-1:00am" ends on the day after date. Hours skipped or repeated by
+// a daylight saving time change follow the DSTPolicy, see WithDSTPolicy.
+//
+// This is a thin wrapper around DefaultClient.Hours().ParseTimeSpan.
 //
-//  startTime, endTime, err := ParseTimeSpan("8:00am-5:00pm", "2006-01-02", )
+//  startTime, endTime, err := ParseTimeSpan("8:00am-5:00pm", "2006-01-02", 41.0428, -82.7258)
 func ParseTimeSpan(timeRange string, date string, latitude float64, longitude float64) (time.Time, time.Time, error) {
-	const (
-		dtz = "%s %s"
-	)
-
-	// Split the time span into start and end times
-	times := strings.Split(timeRange, "-")
-
-	// Detect format used for the time
-	var form string
-	if times[0][len(times[0])-1:] == "m" {
-		form = DateTimeFormat
-	} else {
-		form = DateTimeFormat_M
-	}
-
-	// Get the time zone location of the store
-	loc, err := GetTZLocation(latitude, longitude)
-	if err != nil {
-		return time.Time{}, time.Time{}, err
-	}
-
-	// Parse the start time
-	start, err := time.ParseInLocation(form, fmt.Sprintf(dtz, date, times[0]), loc)
-	if err != nil {
-		return time.Time{}, time.Time{}, err
-	}
-
-	// Parse the end time
-	end, err := time.ParseInLocation(form, fmt.Sprintf(dtz, date, times[1]), loc)
-	if err != nil {
-		return time.Time{}, time.Time{}, err
-	}
-
-	// Require the start time to be before the end time
-	if start.After(end) {
-		return time.Time{}, time.Time{}, ErrTimeParseOrder
-	}
-
-	// Return the start and end times
-	return start, end, nil
+	return DefaultClient.Hours().ParseTimeSpan(timeRange, date, latitude, longitude)
 }
 
-// ParseWeekDayHours takes a time range string and parses it into a start and end time for a given weekday
+// ParseWeekDayHours takes a time range string and parses it into a start and end time for a given weekday.
+//
+// Deprecated: the coordinates are longitude first, unlike ParseTimeSpan.
+// Use ParseWeekDayHoursLatLng.
 func ParseWeekDayHours(weekday time.Weekday, timeRange string, longitude float64, latitude float64) (time.Time, time.Time, error) {
+	return ParseWeekDayHoursLatLng(weekday, timeRange, LatLng{Latitude: latitude, Longitude: longitude})
+}
+
+// ParseWeekDayHoursLatLng takes a time range string and parses it into a start and end time for the
+// given weekday of the current week, in the time zone of the coordinates.
+//  startTime, endTime, err := ParseWeekDayHoursLatLng(time.Monday, "8:00am-5:00pm", LatLng{Latitude: 41.0428, Longitude: -82.7258})
+func ParseWeekDayHoursLatLng(weekday time.Weekday, timeRange string, p LatLng) (time.Time, time.Time, error) {
 
 	// Get the current date in the time zone / location specified
-	loc, err := GetTZLocation(latitude, longitude)
+	loc, err := GetTZLocationLatLng(p)
 	if err != nil {
 		return time.Time{}, time.Time{}, err
 	}
@@ -293,128 +290,131 @@ func ParseWeekDayHours(weekday time.Weekday, timeRange string, longitude float64
 	now_weekday := now.Weekday()
 	if now_weekday == weekday {
 		// It's today!
-		return ParseTimeSpan(timeRange, now.Format(DateFormat), latitude, longitude)
+		return ParseTimeSpan(timeRange, now.Format(DateFormat), p.Latitude, p.Longitude)
 	} else {
 		// It's not today.
 		// Get the next weekday
 		next_weekday := now.AddDate(0, 0, int(weekday-now_weekday)).Format(DateFormat)
-		return ParseTimeSpan(timeRange, next_weekday, latitude, longitude)
+		return ParseTimeSpan(timeRange, next_weekday, p.Latitude, p.Longitude)
+	}
+}
+
+// GetTZLocation returns the time zone location of a coordinate.
+//
+// Deprecated: the coordinates are longitude first, unlike ParseTimeSpan.
+// Use GetTZLocationLatLng.
+func GetTZLocation(longitude float64, latitude float64) (*time.Location, error) {
+	return GetTZLocationLatLng(LatLng{Latitude: latitude, Longitude: longitude})
+}
+
+// Returns the time zone location of a coordinate, i.e. "America/New_York".
+// This is what CoordinateTimeZone resolves stores with. The zone boundaries
+// and the IANA time zone database are embedded, no files are needed at run
+// time. A coordinate in no known zone returns an error wrapping
+// ErrUnknownTimeZone.
+//  loc, err := GetTZLocationLatLng(LatLng{Latitude: 41.0428, Longitude: -82.7258})
+func GetTZLocationLatLng(p LatLng) (*time.Location, error) {
+	// Get the current date in the time zone / location specified
+	locName := tzmap.Lookup(p.Latitude, p.Longitude)
+	if locName == "" {
+		return &time.Location{}, fmt.Errorf("%w: no time zone at %f,%f", ErrUnknownTimeZone, p.Latitude, p.Longitude)
 	}
+	loc, err := time.LoadLocation(locName)
+	if err != nil {
+		return &time.Location{}, err
+	}
+	return loc, nil
 }
 
 // Retrieves the store hours for a given date. This takes into account the store's
-// TimeZone and holiday hours.
+// TimeZone, see TimeZoneResolver, and holiday hours.
 //  // First return pair is the store hours.
 //  // Second return pair is the rx hours.
 //  var storeHours [2]time.Time
 //  var rxHours [2]time.Time
 //  storeHours, rxHours, err := GetStoreHours("2022-05-30", storeData)
+// A department closed that day, or without hours data, returns zero times.
+// A department open 24 hours returns midnight to the following midnight.
+// Use GetStoreDayHours to tell these apart. Overnight hours end on the
+// following day. Breaks during the day, i.e. a pharmacy closed for lunch,
+// are not reflected, see GetStoreIntervals. Hours skipped or repeated by a
+// daylight saving time change follow the DSTPolicy, see WithDSTPolicy.
+// Hours that cannot be parsed return an ErrInvalidHours error, along with
+// the hours of the other department when those are valid.
+//
+// This is a thin wrapper around DefaultClient.Hours().GetStoreHours.
 func GetStoreHours(date string, storeData Store) ([2]time.Time, [2]time.Time, error) {
+	return DefaultClient.Hours().GetStoreHours(date, storeData)
+}
 
-	// Verify date is in the correct format
-	_, err := time.Parse(DateFormat, date)
-	if err != nil {
-		return [2]time.Time{}, [2]time.Time{}, err
-	}
-
-	// Get the current date in the time zone / location specified
-	loc, err := GetTZLocation(storeData.Latitude, storeData.Longitude)
-	if err != nil {
-		return [2]time.Time{}, [2]time.Time{}, err
-	}
-
-	// Return holiday hours for target date if any
-	for i := 0; i < len(storeData.HolidayHours); i++ {
-		holiday := storeData.HolidayHours[i]
-
-		// Verify holiday date is in the correct format
-		_, err := time.Parse(DateFormat, holiday.HolidayDate)
-		if err != nil {
-			return [2]time.Time{}, [2]time.Time{}, err
-		}
-
-		// Check if the holiday date matches the target date
-		if holiday.HolidayDate == date {
-			// Return the holiday hours
-			storeStart, storeEnd, err := ParseTimeSpan(holiday.StoreHours, date, storeData.Latitude, storeData.Longitude)
-			if err != nil {
-				return [2]time.Time{}, [2]time.Time{}, err
-			}
-			rxStart, rxEnd, err := ParseTimeSpan(holiday.PharmacyHours, date, storeData.Latitude, storeData.Longitude)
-			if err != nil {
-				return [2]time.Time{}, [2]time.Time{}, err
-			}
-			return [2]time.Time{storeStart, storeEnd}, [2]time.Time{rxStart, rxEnd}, nil
-		}
-	}
-
-	// Return standard hours for target date
-	dt, err := time.ParseInLocation(DateFormat, date, loc)
-	if err != nil {
-		return [2]time.Time{}, [2]time.Time{}, err
-	}
-	weekday := dt.Weekday()
-
-	// Get unparsed RX and Store hours for a given weekday by using a little bit of code trickery.
-	rxHrs := [7]string{
-		storeData.RXHrsSun,
-		storeData.RXHrsMon,
-		storeData.RXHrsTue,
-		storeData.RXHrsWed,
-		storeData.RXHrsThu,
-		storeData.RXHrsFri,
-		storeData.RXHrsSat,
-	}[weekday]
-	storeHours := [7]string{
-		storeData.StoreHoursSunday,
-		storeData.StoreHoursMonday,
-		storeData.StoreHoursTuesday,
-		storeData.StoreHoursWednesday,
-		storeData.StoreHoursThursday,
-		storeData.StoreHoursFriday,
-		storeData.StoreHoursSaturday,
-	}[weekday]
-
-	// Parse the store hours
-	storeStart, storeEnd, err := ParseTimeSpan(storeHours, date, storeData.Latitude, storeData.Longitude)
-	if err != nil {
-		return [2]time.Time{}, [2]time.Time{}, err
-	}
-
-	// Parse the RX hours
-	rxStart, rxEnd, err := ParseTimeSpan(rxHrs, date, storeData.Latitude, storeData.Longitude)
-	if err != nil {
-		return [2]time.Time{}, [2]time.Time{}, err
-	}
+// Retrieves each opening interval on a given date, holiday hours first. A
+// day with breaks, i.e. "9:00am-1:30pm, 2:00pm-9:00pm", returns one pair
+// per interval. A closed day or a day without hours data returns none.
+// Hours that cannot be parsed return an ErrInvalidHours error, along with
+// the intervals of the other department when those are valid.
+//  // First return is the store intervals.
+//  // Second return is the rx intervals.
+//  storeIntervals, rxIntervals, err := GetStoreIntervals("2022-05-30", storeData)
+//  for _, interval := range rxIntervals {
+//  	fmt.Printf("Pharmacy open %s to %s\n", interval[0], interval[1])
+//  }
+//
+// This is a thin wrapper around DefaultClient.Hours().GetStoreIntervals.
+func GetStoreIntervals(date string, storeData Store) ([][2]time.Time, [][2]time.Time, error) {
+	return DefaultClient.Hours().GetStoreIntervals(date, storeData)
+}
 
-	// log.Printf("Using standard hours %s\n", date)
-	return [2]time.Time{storeStart, storeEnd}, [2]time.Time{rxStart, rxEnd}, nil
+// Retrieves the parsed store hours in effect on a given date, holiday hours
+// first, and their State: open, closed, open 24 hours or unknown. The
+// Source of each tells which hours are in effect. Hours that cannot be
+// parsed are HoursUnknown and return an ErrInvalidHours error, the other
+// department is still returned.
+//  // First return is the store.
+//  // Second return is the pharmacy.
+//  storeDay, rxDay, err := GetStoreDayHours("2022-12-25", storeData)
+//  if rxDay.State == HoursClosed {
+//  	fmt.Println("The pharmacy is closed today")
+//  }
+//
+// This is a thin wrapper around DefaultClient.Hours().GetStoreDayHours.
+func GetStoreDayHours(date string, storeData Store) (DayHours, DayHours, error) {
+	return DefaultClient.Hours().GetStoreDayHours(date, storeData)
 }
 
-// Returns true if the store is open at the given date and time.
+// Returns true if the store is open at the given date and time. A closed
+// day or a day without hours data is reported as not open, a 24 hour day as
+// open. Overnight hours of the previous day, i.e. "7:00am-1:00am", are
+// taken into account after midnight, and breaks between intervals, i.e. a
+// pharmacy closed for lunch, are reported as not open. dateTime may be in
+// any location, it is compared in the store's time zone. Opening times are
+// inclusive and closing times exclusive: a store open "8:00am-10:00pm" is
+// open at 8:00am and closed at 10:00pm. See GetOpenStatus for the reason.
+// Hours that cannot be parsed return an ErrInvalidHours error, along with
+// whether the other department is open when its hours are valid.
 //  // First return is the store.
 //  // Second return is the pharmacy.
-//  loc, _ := time.LoadLocation(storeData.TimeZone)
+//  loc, _ := StoreTimeZone.Location(storeData)
 //  dateTime, _ := time.ParseInLocation("2006-01-02 3:04PM", "2022-05-29 8:00PM", loc)
 //  isOpenStore, isOpenRX, _ := IsStoreOpen(dateTime, storeData)
 //  fmt.Printf("Is Store Open: %t\n", isOpenStore)
 //  fmt.Printf("Is RX Open: %t\n", isOpenRX)
+//
+// This is a thin wrapper around DefaultClient.Hours().IsStoreOpen.
 func IsStoreOpen(dateTime time.Time, storeData Store) (bool, bool, error) {
-	var storeHours [2]time.Time
-	var rxHours [2]time.Time
-
-	storeHours, rxHours, err := GetStoreHours(dateTime.Format(DateFormat), storeData)
-	if err != nil {
-		return false, false, err
-	}
-
-	return dateTime.After(storeHours[0]) && dateTime.Before(storeHours[1]), dateTime.After(rxHours[0]) && dateTime.Before(rxHours[1]), nil
+	return DefaultClient.Hours().IsStoreOpen(dateTime, storeData)
 }
 
 // *****************************************************************************
 // * Private functions
 // *****************************************************************************
 
+// Private function decoding a Result as it is read from r, with a
+// json.Decoder, so the input is never held in memory whole. A Status other
+// than "SUCCESS" is returned as an *APIError with the given HTTP status code.
+func parseResult(r io.Reader, statusCode int) (Result, error) {
+	return decodeResult(r, statusCode, nil)
+}
+
 // Private function to strip all non numeric characters from a string
 //  removeNonNumeric("(419) 555-1212") -> "4195551212"
 func removeNonNumeric(s string) string {
@@ -434,6 +434,13 @@ func GetStoreAddress(storeData Store) string {
 //  RADIUS: The radius of the store. i.e. 3 (required - must be between 0 and 25. 0 = max radius)
 //  RETURNS: The URL for the API call.
 func __getStoreDataURL(address string, radius float64) (string, error) {
+	return buildStoreDataURL(riteAidBaseURL, address, radius, SearchOptions{})
+}
+
+// Private function to build the getStoreData URL against the given base URL.
+// This allows a Client to be pointed at a test server or proxy. Only the
+// SearchOptions sent to the API are used.
+func buildStoreDataURL(baseURL string, address string, radius float64, opts SearchOptions) (string, error) {
 	// Require radius to be between 0 and 25 (0 is default will list all withing 25 mile radius)
 	var err error
 	if radius <= 0 {
@@ -442,21 +449,11 @@ func __getStoreDataURL(address string, radius float64) (string, error) {
 		err = ErrRadiusOverMax // Non critical error
 	}
 	encodedAddress := url.QueryEscape(address)
-	url := fmt.Sprintf(riteAidAPIURL, encodedAddress, radius)
+	url := baseURL + fmt.Sprintf(riteAidAPIQuery, opts.PharmacyOnly, !opts.GlobalZipCodeOptional, encodedAddress, radius)
 
 	return url, err
 }
 
-func GetTZLocation(longitude float64, latitude float64) (*time.Location, error) {
-	// Get the current date in the time zone / location specified
-	locName := timezonemapper.LatLngToTimezoneString(latitude, longitude)
-	loc, err := time.LoadLocation(locName)
-	if err != nil {
-		return &time.Location{}, err
-	}
-	return loc, nil
-}
-
 // *****************************************************************************
 // !! DEPRECIATED functions
 // *****************************************************************************
@@ -0,0 +1,264 @@
+// Package riteaidtest provides a fake RiteAid getStores API for testing
+// code built on the riteaid package without the network.
+//
+//	srv := riteaidtest.NewServer(riteaid.Store{StoreNumber: 3357, Latitude: 41.0524, Longitude: -82.7255})
+//	defer srv.Close()
+//	srv.AddAddress("4 Walton St E, Willard, OH 44890", riteaid.LatLng{Latitude: 41.0524, Longitude: -82.7255})
+//
+//	client := srv.Client()
+//	result, err := client.Search("4 Walton St E, Willard, OH 44890", 0.5)
+package riteaidtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	riteaid "github.com/zinthose/RiteAidStoreSearch"
+)
+
+// Path the fake getStores endpoint is served on
+const Path = "/services/ext/v2/stores/getStores"
+
+// Largest radius honored by the fake, matching the real API
+const MaxRadius = 25
+
+// Server is a fake RiteAid getStores API. Stores are returned when they are
+// within the requested radius of the geocoded address. Addresses are
+// geocoded with the table filled by AddAddress, or parsed when given as
+// "latitude,longitude". Any other address fails with ErrCde
+// "INVALID_ADDRESS", the real API's response to it has not been recorded.
+//
+// All methods are safe to call while requests are being served.
+type Server struct {
+	// The underlying test server
+	*httptest.Server
+
+	// URL of the getStores endpoint, for riteaid.WithBaseURL
+	BaseURL string
+
+	mu         sync.Mutex
+	stores     []riteaid.Store
+	addresses  map[string]riteaid.LatLng
+	ambiguous  map[string][]riteaid.ResolvedAddress
+	failure    *riteaid.Result
+	malformed  bool
+	delay      time.Duration
+	httpStatus int
+	requests   []*http.Request
+}
+
+// NewServer starts a fake API serving the given stores. Call Close when done.
+func NewServer(stores ...riteaid.Store) *Server {
+	s := &Server{addresses: make(map[string]riteaid.LatLng), ambiguous: make(map[string][]riteaid.ResolvedAddress)}
+	s.stores = append(s.stores, stores...)
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.BaseURL = s.Server.URL + Path
+	return s
+}
+
+// Client returns a riteaid.Client pointed at the fake. Retries are disabled
+// so simulated failures are returned right away; opts are applied after and
+// may override this.
+func (s *Server) Client(opts ...riteaid.Option) *riteaid.Client {
+	defaults := []riteaid.Option{
+		riteaid.WithBaseURL(s.BaseURL),
+		riteaid.WithHTTPClient(s.Server.Client()),
+		riteaid.WithRetryPolicy(riteaid.NoRetry),
+	}
+	return riteaid.NewClient(append(defaults, opts...)...)
+}
+
+// AddStores adds stores to the fixtures served.
+func (s *Server) AddStores(stores ...riteaid.Store) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stores = append(s.stores, stores...)
+}
+
+// AddAddress teaches the fake geocoder where an address is. Matching ignores
+// case and extra white space.
+func (s *Server) AddAddress(address string, at riteaid.LatLng) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addresses[normalize(address)] = at
+}
+
+// AddAmbiguous makes an address match several places. The stores around the
+// first candidate are returned, with every candidate listed in
+// Data.AmbiguousAddresses. This follows the unverified shape of
+// riteaid.Data.AmbiguousAddresses, the real API has not been recorded
+// returning it.
+func (s *Server) AddAmbiguous(address string, candidates ...riteaid.ResolvedAddress) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ambiguous[normalize(address)] = candidates
+	if len(candidates) > 0 {
+		s.addresses[normalize(address)] = candidates[0].Location()
+	}
+}
+
+// FailWith makes every following request return a result with the given
+// Status (anything but "SUCCESS") and error fields.
+func (s *Server) FailWith(status string, errCde string, errMsg string, errMsgDtl string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failure = &riteaid.Result{Status: status, ErrCde: errCde, ErrMsg: errMsg, ErrMsgDtl: errMsgDtl}
+}
+
+// SetMalformed makes every following request return a truncated JSON body.
+func (s *Server) SetMalformed(malformed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.malformed = malformed
+}
+
+// SetDelay makes every following request wait d before responding. The wait
+// ends early if the client gives up.
+func (s *Server) SetDelay(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delay = d
+}
+
+// SetHTTPError makes every following request fail with the given HTTP status
+// code and an HTML body. Use 0 to stop failing.
+func (s *Server) SetHTTPError(statusCode int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.httpStatus = statusCode
+}
+
+// Reset clears every simulated failure and delay. Stores, addresses and the
+// request log are kept.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failure = nil
+	s.malformed = false
+	s.delay = 0
+	s.httpStatus = 0
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []*http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*http.Request(nil), s.requests...)
+}
+
+// Private method handling a getStores request.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r)
+	delay, httpStatus, malformed, failure := s.delay, s.httpStatus, s.malformed, s.failure
+	s.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if httpStatus != 0 {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(httpStatus)
+		w.Write([]byte("<html><body><h1>" + http.StatusText(httpStatus) + "</h1></body></html>"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	if malformed {
+		w.Write([]byte(`{"data":{"stores":[{"storeNumber":`))
+		return
+	}
+	if failure != nil {
+		json.NewEncoder(w).Encode(failure)
+		return
+	}
+
+	json.NewEncoder(w).Encode(s.search(r))
+}
+
+// Private method building the result for a request.
+func (s *Server) search(r *http.Request) riteaid.Result {
+	query := r.URL.Query()
+	address := query.Get("address")
+
+	center, ok := s.geocode(address)
+	if !ok {
+		return riteaid.Result{Status: "FAILURE", ErrCde: "INVALID_ADDRESS", ErrMsg: "Unable to resolve the address", ErrMsgDtl: address}
+	}
+
+	radius, err := strconv.ParseFloat(query.Get("radius"), 64)
+	if err != nil || radius <= 0 {
+		return riteaid.Result{Status: "FAILURE", ErrCde: "INVALID_RADIUS", ErrMsg: "Invalid radius", ErrMsgDtl: query.Get("radius")}
+	}
+	if radius > MaxRadius {
+		radius = MaxRadius
+	}
+
+	s.mu.Lock()
+	ambiguous := s.ambiguous[normalize(address)]
+	stores := make([]riteaid.Store, 0, len(s.stores))
+	for _, store := range s.stores {
+		store.MilesFromCenter = riteaid.DistanceMiles(center, riteaid.LatLng{Latitude: store.Latitude, Longitude: store.Longitude})
+		if store.MilesFromCenter <= radius {
+			stores = append(stores, store)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.SliceStable(stores, func(i, j int) bool {
+		return stores[i].MilesFromCenter < stores[j].MilesFromCenter
+	})
+
+	return riteaid.Result{
+		Status: "SUCCESS",
+		Data: riteaid.Data{
+			Stores: stores,
+			ResolvedAddress: riteaid.ResolvedAddress{
+				AddressLine:      address,
+				FormattedAddress: address,
+				DisplayName:      address,
+				Confidence:       "High",
+				Latitude:         center.Latitude,
+				Longitude:        center.Longitude,
+			},
+			AmbiguousAddresses: ambiguous,
+		},
+	}
+}
+
+// Private method geocoding an address with the table or as "lat,lng".
+func (s *Server) geocode(address string) (riteaid.LatLng, bool) {
+	s.mu.Lock()
+	at, ok := s.addresses[normalize(address)]
+	s.mu.Unlock()
+	if ok {
+		return at, true
+	}
+
+	parts := strings.Split(address, ",")
+	if len(parts) != 2 {
+		return riteaid.LatLng{}, false
+	}
+	lat, err1 := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	lng, err2 := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err1 != nil || err2 != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return riteaid.LatLng{}, false
+	}
+	return riteaid.LatLng{Latitude: lat, Longitude: lng}, true
+}
+
+// Private function normalizing an address for the geocoder table.
+func normalize(address string) string {
+	return strings.Join(strings.Fields(strings.ToLower(address)), " ")
+}
@@ -0,0 +1,110 @@
+package riteaidtest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	riteaid "github.com/zinthose/RiteAidStoreSearch"
+)
+
+var willard = riteaid.LatLng{Latitude: 41.0524, Longitude: -82.7255}
+
+func newTestServer(t *testing.T) *Server {
+	srv := NewServer(
+		riteaid.Store{StoreNumber: 3357, Name: "Rite Aid", Latitude: 41.0524, Longitude: -82.7255},
+		riteaid.Store{StoreNumber: 1000, Name: "Rite Aid", Latitude: 41.2420, Longitude: -82.6157}, // Norwalk, ~14 miles
+	)
+	t.Cleanup(srv.Close)
+	srv.AddAddress("4 Walton St E, Willard, OH 44890", willard)
+	return srv
+}
+
+func TestServerSearch(t *testing.T) {
+	srv := newTestServer(t)
+	client := srv.Client()
+
+	result, err := client.Search("4 walton st e,  willard, oh 44890", 0.5)
+	if err != nil || len(result.Data.Stores) != 1 || result.Data.Stores[0].StoreNumber != 3357 {
+		t.Fatalf("Search(<willard>, 0.5) = %+v, %v, want store 3357", result.Data.Stores, err)
+	}
+	if result.Data.ResolvedAddress.Latitude != willard.Latitude {
+		t.Errorf("ResolvedAddress.Latitude = %g, want %g", result.Data.ResolvedAddress.Latitude, willard.Latitude)
+	}
+
+	// Coordinates are geocoded as is, stores are sorted by distance
+	result, err = client.Search(willard.String(), 25)
+	if err != nil || len(result.Data.Stores) != 2 || result.Data.Stores[1].StoreNumber != 1000 || result.Data.Stores[1].MilesFromCenter < 10 {
+		t.Errorf("Search(<coordinates>, 25) = %+v, %v, want stores 3357 and 1000", result.Data.Stores, err)
+	}
+
+	// Unknown addresses fail
+	_, err = client.Search("nowhere", 1)
+	var apiErr *riteaid.APIError
+	if !errors.As(err, &apiErr) || apiErr.ErrCde != "INVALID_ADDRESS" {
+		t.Errorf("Search(nowhere) ERROR = %v, want INVALID_ADDRESS", err)
+	}
+
+	if len(srv.Requests()) != 3 {
+		t.Errorf("Requests() = %d, want 3", len(srv.Requests()))
+	}
+}
+
+func TestServerFailures(t *testing.T) {
+	srv := newTestServer(t)
+	client := srv.Client()
+	address := "4 Walton St E, Willard, OH 44890"
+
+	srv.FailWith("FAILURE", "E1", "Broken", "details")
+	_, err := client.Search(address, 1)
+	var apiErr *riteaid.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != "FAILURE" || apiErr.ErrCde != "E1" {
+		t.Errorf("Search(<FailWith>) ERROR = %v, want E1", err)
+	}
+	srv.Reset()
+
+	srv.SetHTTPError(http.StatusServiceUnavailable)
+	_, err = client.Search(address, 1)
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Search(<SetHTTPError>) ERROR = %v, want HTTP 503", err)
+	}
+	srv.Reset()
+
+	srv.SetMalformed(true)
+	if _, err = client.Search(address, 1); err == nil || errors.As(err, &apiErr) {
+		t.Errorf("Search(<SetMalformed>) ERROR = %v, want a JSON error", err)
+	}
+	srv.Reset()
+
+	srv.SetDelay(time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err = client.SearchContext(ctx, address, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Search(<SetDelay>) ERROR = %v, want %v", err, context.DeadlineExceeded)
+	}
+	srv.Reset()
+
+	if _, err = client.Search(address, 1); err != nil {
+		t.Errorf("Search(<Reset>) ERROR: %q", err)
+	}
+}
+
+func TestServerAmbiguous(t *testing.T) {
+	srv := newTestServer(t)
+	client := srv.Client()
+	srv.AddAmbiguous("Willard",
+		riteaid.ResolvedAddress{FormattedAddress: "Willard, OH", Latitude: willard.Latitude, Longitude: willard.Longitude},
+		riteaid.ResolvedAddress{FormattedAddress: "Willard, MO", Latitude: 37.3051, Longitude: -93.4285},
+	)
+
+	result, err := client.Search("willard", 1)
+	var ambiguous *riteaid.AmbiguousAddressError
+	if !errors.As(err, &ambiguous) || len(ambiguous.Candidates) != 2 || ambiguous.Candidates[1].FormattedAddress != "Willard, MO" {
+		t.Fatalf("Search(willard) ERROR = %v, want both candidates", err)
+	}
+	if !result.Ambiguous() || len(result.Data.Stores) != 1 || result.Data.Stores[0].StoreNumber != 3357 {
+		t.Errorf("Search(willard) = %+v, want store 3357 around the first candidate", result.Data.Stores)
+	}
+}
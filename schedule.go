@@ -0,0 +1,486 @@
+package riteaid
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error returned when an hours string is not in a known format
+var ErrInvalidHours = errors.New("hours are not in a known format")
+
+// Error returned when a weekday or Department is out of range
+var ErrInvalidDay = errors.New("weekday or department out of range")
+
+// Department of a store that keeps its own hours.
+type Department int
+
+const (
+	DepartmentStore Department = iota
+	DepartmentPharmacy
+)
+
+// Departments lists every Department in order.
+var Departments = []Department{DepartmentStore, DepartmentPharmacy}
+
+// String returns "store" or "pharmacy".
+func (d Department) String() string {
+	switch d {
+	case DepartmentStore:
+		return "store"
+	case DepartmentPharmacy:
+		return "pharmacy"
+	}
+	return "Department(" + strconv.Itoa(int(d)) + ")"
+}
+
+// TimeOfDay is a wall clock time, in minutes after midnight.
+type TimeOfDay int
+
+// Private pattern matching a time such as "8:00am", "8:00 PM", "8pm" or "20:00"
+var timeOfDayPattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(?:([ap])\.?m\.?)?$`)
+
+// NewTimeOfDay returns the TimeOfDay for an hour (0-23) and minute.
+func NewTimeOfDay(hour int, minute int) TimeOfDay {
+	return TimeOfDay(hour*60 + minute)
+}
+
+// ParseTimeOfDay parses a time as written by the RiteAid API.
+//
+//	ParseTimeOfDay("8:00am")  -> 8:00am
+//	ParseTimeOfDay("8:00 PM") -> 8:00pm
+//	ParseTimeOfDay("20:00")   -> 8:00pm
+func ParseTimeOfDay(s string) (TimeOfDay, error) {
+	match := timeOfDayPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if match == nil {
+		return 0, fmt.Errorf("%w: time %q", ErrInvalidHours, s)
+	}
+
+	hour, _ := strconv.Atoi(match[1])
+	minute := 0
+	if match[2] != "" {
+		minute, _ = strconv.Atoi(match[2])
+	}
+	switch match[3] {
+	case "a", "p":
+		if hour < 1 || hour > 12 {
+			return 0, fmt.Errorf("%w: time %q", ErrInvalidHours, s)
+		}
+		hour %= 12
+		if match[3] == "p" {
+			hour += 12
+		}
+	default:
+		if hour > 23 {
+			return 0, fmt.Errorf("%w: time %q", ErrInvalidHours, s)
+		}
+	}
+	if minute > 59 {
+		return 0, fmt.Errorf("%w: time %q", ErrInvalidHours, s)
+	}
+	return NewTimeOfDay(hour, minute), nil
+}
+
+// Hour returns the hour, 0 to 23.
+func (t TimeOfDay) Hour() int {
+	return int(t) / 60 % 24
+}
+
+// Minute returns the minute, 0 to 59.
+func (t TimeOfDay) Minute() int {
+	return int(t) % 60
+}
+
+// String returns the time in TimeFormat, i.e. "8:00am".
+func (t TimeOfDay) String() string {
+	return time.Date(0, 1, 1, t.Hour(), t.Minute(), 0, 0, time.UTC).Format(TimeFormat)
+}
+
+// On returns the time on the given day, in the day's location. A TimeOfDay
+// of 24 hours or more falls on a following day, 24:00 being the midnight
+// that ends the day. Times skipped or repeated by a daylight saving time
+// change follow time.Date, which is DSTEarliest.
+func (t TimeOfDay) On(day time.Time) time.Time {
+	year, month, date := day.Date()
+	return time.Date(year, month, date, int(t)/60, int(t)%60, 0, 0, day.Location())
+}
+
+// Interval is a span of opening hours on a day, open from Start until End.
+// An End of 24:00 or later closes on the next day, an overnight span.
+type Interval struct {
+	Start TimeOfDay
+	End   TimeOfDay
+}
+
+// String returns the interval as written by the RiteAid API, i.e.
+// "8:00am-10:00pm".
+func (i Interval) String() string {
+	return i.Start.String() + "-" + i.End.String()
+}
+
+// On returns the start and end of the interval on the given day. The end of
+// an overnight interval falls on the next day.
+func (i Interval) On(day time.Time) (time.Time, time.Time) {
+	return i.Start.On(day), i.End.On(day)
+}
+
+// Private function parsing a single "start-end" interval. Spans ending at
+// or before their start run past midnight.
+func parseInterval(s string) (Interval, error) {
+	times := strings.Split(s, "-")
+	if len(times) != 2 {
+		return Interval{}, fmt.Errorf("%w: %q", ErrInvalidHours, s)
+	}
+	start, err := ParseTimeOfDay(times[0])
+	if err != nil {
+		return Interval{}, err
+	}
+	end, err := ParseTimeOfDay(times[1])
+	if err != nil {
+		return Interval{}, err
+	}
+
+	// An end at or before the start closes on the next day, i.e.
+	// "7:00am-1:00am" or "12:00am-12:00am"
+	if end <= start {
+		end += NewTimeOfDay(24, 0)
+	}
+	return Interval{Start: start, End: end}, nil
+}
+
+// HoursState tells what kind of hours a DayHours holds.
+type HoursState int
+
+const (
+	// No hours data, the API returned an empty string
+	HoursUnknown HoursState = iota
+
+	// Open during the Intervals
+	HoursOpen
+
+	// Closed all day, i.e. "Closed"
+	HoursClosed
+
+	// Open all day, i.e. "Open 24 Hours"
+	HoursOpen24
+)
+
+// String returns "unknown", "open", "closed" or "open 24 hours".
+func (s HoursState) String() string {
+	switch s {
+	case HoursUnknown:
+		return "unknown"
+	case HoursOpen:
+		return "open"
+	case HoursClosed:
+		return "closed"
+	case HoursOpen24:
+		return "open 24 hours"
+	}
+	return "HoursState(" + strconv.Itoa(int(s)) + ")"
+}
+
+// HoursSource tells where the hours in effect on a day come from.
+type HoursSource int
+
+const (
+	// The regular weekly hours of the store
+	SourceRegular HoursSource = iota
+
+	// The HolidayHours of the store for the date
+	SourceHoliday
+
+	// The PickupDateAndTimes.SpecialHours of the pharmacy for the date. These
+	// are prescription pickup windows, only reported by GetOpenStatus as
+	// OpenStatus.Pickup. They never replace the pharmacy hours.
+	SourceSpecial
+)
+
+// String returns "regular", "holiday" or "special".
+func (s HoursSource) String() string {
+	switch s {
+	case SourceRegular:
+		return "regular"
+	case SourceHoliday:
+		return "holiday"
+	case SourceSpecial:
+		return "special"
+	}
+	return "HoursSource(" + strconv.Itoa(int(s)) + ")"
+}
+
+// Private patterns matching the hours strings of closed and 24 hour days
+var (
+	closedPattern = regexp.MustCompile(`^closed?(\s+all\s+day)?$`)
+	open24Pattern = regexp.MustCompile(`^(open\s*)?(24\s*(hours|hrs?|h)(\s+a\s+day)?|24/7)$`)
+)
+
+// DayHours is the parsed opening hours of one department on one day. The
+// zero value is HoursUnknown.
+type DayHours struct {
+	// The hours string the API returned, i.e. "8:00am-10:00pm"
+	Raw string
+
+	// The kind of hours
+	State HoursState
+
+	// The opening intervals in order. Empty when closed or unknown, midnight
+	// to midnight when open 24 hours.
+	Intervals []Interval
+
+	// Where the hours come from, set by GetStoreDayHours and GetOpenStatus
+	Source HoursSource
+}
+
+// Private pattern separating the intervals of a day with breaks
+var intervalSeparator = regexp.MustCompile(`\s*[,;]\s*`)
+
+// ParseDayHours parses an hours string as returned by the RiteAid API.
+// Besides "start-end" spans it recognizes closed days ("Closed"), 24 hour
+// operation ("Open 24 Hours") and missing data (an empty string). Days with
+// breaks list their intervals separated by commas or semicolons, i.e.
+// "9:00am-1:30pm, 2:00pm-9:00pm". Intervals are sorted and must not overlap.
+//
+//	hours, err := ParseDayHours("8:00am-10:00pm")
+func ParseDayHours(s string) (DayHours, error) {
+	normalized := strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	switch {
+	case normalized == "":
+		return DayHours{Raw: s, State: HoursUnknown}, nil
+	case closedPattern.MatchString(normalized):
+		return DayHours{Raw: s, State: HoursClosed}, nil
+	case open24Pattern.MatchString(normalized):
+		return DayHours{Raw: s, State: HoursOpen24, Intervals: []Interval{{Start: 0, End: NewTimeOfDay(24, 0)}}}, nil
+	}
+
+	parts := intervalSeparator.Split(strings.TrimSpace(s), -1)
+	intervals := make([]Interval, len(parts))
+	for i, part := range parts {
+		interval, err := parseInterval(part)
+		if err != nil {
+			return DayHours{Raw: s}, err
+		}
+		intervals[i] = interval
+	}
+
+	// Require the intervals to be apart from each other
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].Start < intervals[j].Start })
+	for i := 1; i < len(intervals); i++ {
+		if intervals[i].Start < intervals[i-1].End {
+			return DayHours{Raw: s}, fmt.Errorf("%w: %s overlaps %s", ErrInvalidHours, intervals[i], intervals[i-1])
+		}
+	}
+	return DayHours{Raw: s, State: HoursOpen, Intervals: intervals}, nil
+}
+
+// String returns the hours as returned by the API, or formatted from the
+// State and intervals when there is no Raw string.
+func (h DayHours) String() string {
+	if h.Raw != "" {
+		return h.Raw
+	}
+	switch h.State {
+	case HoursClosed:
+		return "Closed"
+	case HoursOpen24:
+		return "Open 24 Hours"
+	}
+	parts := make([]string, len(h.Intervals))
+	for i, interval := range h.Intervals {
+		parts[i] = interval.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// On returns the start and end time of each interval on the given day, in
+// order. Nil is returned when there are no intervals.
+func (h DayHours) On(day time.Time) [][2]time.Time {
+	var spans [][2]time.Time
+	for _, interval := range h.Intervals {
+		start, end := interval.On(day)
+		spans = append(spans, [2]time.Time{start, end})
+	}
+	return spans
+}
+
+// WeeklySchedule is the regular opening hours of a store, per weekday and
+// per department, parsed once from the fourteen hours strings of a Store.
+// Holiday hours are not part of it.
+//
+//	schedule, err := NewWeeklySchedule(storeData)
+//	fmt.Println(schedule.Day(time.Sunday, DepartmentPharmacy)) // "10:00am-6:00pm"
+type WeeklySchedule struct {
+	// Hours of each department indexed by Department, then time.Weekday
+	hours [2][7]DayHours
+
+	// Why the hours of a day could not be parsed, same indexes
+	errs [2][7]error
+}
+
+// NewWeeklySchedule parses the regular hours of a store. The first hours
+// string that cannot be parsed is reported along with its weekday and
+// department. The schedule is complete even then: days that cannot be
+// parsed are HoursUnknown and Err reports why.
+func NewWeeklySchedule(storeData Store) (WeeklySchedule, error) {
+	var schedule WeeklySchedule
+	var first error
+	for _, dept := range Departments {
+		raw := weeklyHours(&storeData, dept)
+		for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+			hours, err := ParseDayHours(*raw[weekday])
+			if err != nil {
+				err = fmt.Errorf("%s %s hours: %w", weekday, dept, err)
+				schedule.errs[dept][weekday] = err
+				if first == nil {
+					first = err
+				}
+			}
+			schedule.hours[dept][weekday] = hours
+		}
+	}
+	return schedule, first
+}
+
+// Day returns the hours of a department on a weekday. An unknown weekday
+// or department returns the zero DayHours, HoursUnknown.
+func (w WeeklySchedule) Day(weekday time.Weekday, dept Department) DayHours {
+	if validDay(weekday, dept) != nil {
+		return DayHours{}
+	}
+	return w.hours[dept][weekday]
+}
+
+// Err returns why the hours of a department on a weekday could not be
+// parsed, nil when they were. An unknown weekday or department returns an
+// ErrInvalidDay error.
+func (w WeeklySchedule) Err(weekday time.Weekday, dept Department) error {
+	if err := validDay(weekday, dept); err != nil {
+		return err
+	}
+	return w.errs[dept][weekday]
+}
+
+// Set replaces the hours of a department on a weekday. An unknown weekday
+// or department changes nothing and returns an ErrInvalidDay error.
+func (w *WeeklySchedule) Set(weekday time.Weekday, dept Department, hours DayHours) error {
+	if err := validDay(weekday, dept); err != nil {
+		return err
+	}
+	w.hours[dept][weekday] = hours
+	w.errs[dept][weekday] = nil
+	return nil
+}
+
+// Private function returning an ErrInvalidDay error unless weekday and
+// dept index a WeeklySchedule.
+func validDay(weekday time.Weekday, dept Department) error {
+	if weekday < time.Sunday || weekday > time.Saturday || dept < DepartmentStore || dept > DepartmentPharmacy {
+		return fmt.Errorf("%w: %s %s", ErrInvalidDay, weekday, dept)
+	}
+	return nil
+}
+
+// Range calls fn for every weekday, Sunday first, and department until fn
+// returns false.
+//
+//	schedule.Range(func(weekday time.Weekday, dept riteaid.Department, hours riteaid.DayHours) bool {
+//		fmt.Printf("%-9s %-8s %s\n", weekday, dept, hours)
+//		return true
+//	})
+func (w WeeklySchedule) Range(fn func(weekday time.Weekday, dept Department, hours DayHours) bool) {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		for _, dept := range Departments {
+			if !fn(weekday, dept, w.hours[dept][weekday]) {
+				return
+			}
+		}
+	}
+}
+
+// Apply writes the schedule back into the hours strings of a Store.
+func (w WeeklySchedule) Apply(storeData *Store) {
+	for _, dept := range Departments {
+		raw := weeklyHours(storeData, dept)
+		for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+			*raw[weekday] = w.hours[dept][weekday].String()
+		}
+	}
+}
+
+// Private type used to serialize a WeeklySchedule
+type weeklyScheduleJSON struct {
+	Store    map[string]string `json:"store"`
+	Pharmacy map[string]string `json:"pharmacy"`
+}
+
+// MarshalJSON encodes the schedule keyed by department and lower case
+// weekday.
+//
+//	{"store":{"sunday":"8:00am-10:00pm",...},"pharmacy":{"sunday":"10:00am-6:00pm",...}}
+func (w WeeklySchedule) MarshalJSON() ([]byte, error) {
+	encoded := weeklyScheduleJSON{Store: make(map[string]string), Pharmacy: make(map[string]string)}
+	w.Range(func(weekday time.Weekday, dept Department, hours DayHours) bool {
+		days := encoded.Store
+		if dept == DepartmentPharmacy {
+			days = encoded.Pharmacy
+		}
+		days[strings.ToLower(weekday.String())] = hours.String()
+		return true
+	})
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON decodes a schedule encoded by MarshalJSON.
+func (w *WeeklySchedule) UnmarshalJSON(data []byte) error {
+	var encoded weeklyScheduleJSON
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+
+	var schedule WeeklySchedule
+	for _, dept := range Departments {
+		days := encoded.Store
+		if dept == DepartmentPharmacy {
+			days = encoded.Pharmacy
+		}
+		for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+			hours, err := ParseDayHours(days[strings.ToLower(weekday.String())])
+			if err != nil {
+				return fmt.Errorf("%s %s hours: %w", weekday, dept, err)
+			}
+			schedule.hours[dept][weekday] = hours
+		}
+	}
+	*w = schedule
+	return nil
+}
+
+// Private function returning pointers to the hours strings of a department
+// indexed by time.Weekday.
+func weeklyHours(storeData *Store, dept Department) [7]*string {
+	if dept == DepartmentPharmacy {
+		return [7]*string{
+			&storeData.RXHrsSun,
+			&storeData.RXHrsMon,
+			&storeData.RXHrsTue,
+			&storeData.RXHrsWed,
+			&storeData.RXHrsThu,
+			&storeData.RXHrsFri,
+			&storeData.RXHrsSat,
+		}
+	}
+	return [7]*string{
+		&storeData.StoreHoursSunday,
+		&storeData.StoreHoursMonday,
+		&storeData.StoreHoursTuesday,
+		&storeData.StoreHoursWednesday,
+		&storeData.StoreHoursThursday,
+		&storeData.StoreHoursFriday,
+		&storeData.StoreHoursSaturday,
+	}
+}
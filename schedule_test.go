@@ -0,0 +1,394 @@
+package riteaid
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Private function returning a store open 8am-10pm with a pharmacy open
+// 9am-9pm on weekdays and shorter weekend pharmacy hours.
+func scheduleStore() Store {
+	return Store{
+		StoreHoursMonday:    "8:00am-10:00pm",
+		StoreHoursTuesday:   "8:00am-10:00pm",
+		StoreHoursWednesday: "8:00am-10:00pm",
+		StoreHoursThursday:  "8:00am-10:00pm",
+		StoreHoursFriday:    "8:00am-10:00pm",
+		StoreHoursSaturday:  "8:00am-10:00pm",
+		StoreHoursSunday:    "8:00 AM-10:00 PM",
+		RXHrsMon:            "9:00am-9:00pm",
+		RXHrsTue:            "9:00am-9:00pm",
+		RXHrsWed:            "9:00am-9:00pm",
+		RXHrsThu:            "9:00am-9:00pm",
+		RXHrsFri:            "9:00am-9:00pm",
+		RXHrsSat:            "9:00am-6:00pm",
+		RXHrsSun:            "10:00am-6:00pm",
+	}
+}
+
+func TestParseTimeOfDay(t *testing.T) {
+	tests := map[string]TimeOfDay{
+		"8:00am":  NewTimeOfDay(8, 0),
+		"8:30 PM": NewTimeOfDay(20, 30),
+		"12:00am": NewTimeOfDay(0, 0),
+		"12:15pm": NewTimeOfDay(12, 15),
+		"9pm":     NewTimeOfDay(21, 0),
+		"9 a.m.":  NewTimeOfDay(9, 0),
+		"20:45":   NewTimeOfDay(20, 45),
+	}
+	for s, want := range tests {
+		if got, err := ParseTimeOfDay(s); err != nil || got != want {
+			t.Errorf("ParseTimeOfDay(%q) = %s, %v, want %s", s, got, err, want)
+		}
+	}
+
+	for _, s := range []string{"", "noon", "13:00pm", "8:75am", "24:00"} {
+		if _, err := ParseTimeOfDay(s); !errors.Is(err, ErrInvalidHours) {
+			t.Errorf("ParseTimeOfDay(%q) ERROR = %v, want %v", s, err, ErrInvalidHours)
+		}
+	}
+
+	if got := NewTimeOfDay(21, 5).String(); got != "9:05pm" {
+		t.Errorf("TimeOfDay.String() = %q, want %q", got, "9:05pm")
+	}
+}
+
+func TestParseDayHours(t *testing.T) {
+	hours, err := ParseDayHours("8:00 AM-10:00 PM")
+	if err != nil || len(hours.Intervals) != 1 || hours.Intervals[0] != (Interval{NewTimeOfDay(8, 0), NewTimeOfDay(22, 0)}) {
+		t.Errorf("ParseDayHours() = %+v, %v, want 8am to 10pm", hours, err)
+	}
+	if hours.String() != "8:00 AM-10:00 PM" || (DayHours{Intervals: hours.Intervals}).String() != "8:00am-10:00pm" {
+		t.Errorf("DayHours.String() = %q, want the raw or formatted hours", hours.String())
+	}
+
+	day := time.Date(2022, 5, 30, 15, 0, 0, 0, time.UTC)
+	start, end := hours.Intervals[0].On(day)
+	if start != time.Date(2022, 5, 30, 8, 0, 0, 0, time.UTC) || end != time.Date(2022, 5, 30, 22, 0, 0, 0, time.UTC) {
+		t.Errorf("Interval.On() = %s, %s, want 8am and 10pm on 2022-05-30", start, end)
+	}
+
+	// Reversed spans run overnight
+	overnight, err := ParseDayHours("7:00am-1:00am")
+	if err != nil || overnight.Intervals[0] != (Interval{NewTimeOfDay(7, 0), NewTimeOfDay(25, 0)}) {
+		t.Errorf("ParseDayHours(<overnight>) = %+v, %v, want 7am to 1am the next day", overnight, err)
+	}
+	if _, end := overnight.Intervals[0].On(day); end != time.Date(2022, 5, 31, 1, 0, 0, 0, time.UTC) {
+		t.Errorf("Interval.On(<overnight>) end = %s, want 1am on 2022-05-31", end)
+	}
+	if got := (DayHours{Intervals: overnight.Intervals}).String(); got != "7:00am-1:00am" {
+		t.Errorf("DayHours.String(<overnight>) = %q, want %q", got, "7:00am-1:00am")
+	}
+	if _, err := ParseDayHours("8:00am"); !errors.Is(err, ErrInvalidHours) {
+		t.Errorf("ParseDayHours(<no end>) ERROR = %v, want %v", err, ErrInvalidHours)
+	}
+}
+
+func TestWeeklySchedule(t *testing.T) {
+	storeData := scheduleStore()
+	schedule, err := NewWeeklySchedule(storeData)
+	if err != nil {
+		t.Fatalf("NewWeeklySchedule() ERROR: %q", err)
+	}
+
+	if got := schedule.Day(time.Sunday, DepartmentPharmacy).String(); got != "10:00am-6:00pm" {
+		t.Errorf("Day(Sunday, pharmacy) = %q, want %q", got, "10:00am-6:00pm")
+	}
+	if got := schedule.Day(time.Saturday, DepartmentStore).Intervals[0].End; got != NewTimeOfDay(22, 0) {
+		t.Errorf("Day(Saturday, store) closes at %s, want 10:00pm", got)
+	}
+
+	// Range visits every weekday and department, Sunday first
+	var visited []string
+	schedule.Range(func(weekday time.Weekday, dept Department, hours DayHours) bool {
+		visited = append(visited, weekday.String()[:3]+" "+dept.String())
+		return true
+	})
+	if len(visited) != 14 || visited[0] != "Sun store" || visited[1] != "Sun pharmacy" || visited[13] != "Sat pharmacy" {
+		t.Errorf("Range() visited %v", visited)
+	}
+
+	// JSON round trip
+	data, err := json.Marshal(schedule)
+	if err != nil || !strings.Contains(string(data), `"pharmacy":{"friday":"9:00am-9:00pm"`) {
+		t.Fatalf("json.Marshal() = %s, %v", data, err)
+	}
+	var decoded WeeklySchedule
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Day(time.Sunday, DepartmentStore).String() != "8:00 AM-10:00 PM" {
+		t.Errorf("json.Unmarshal() = %v, %v, want the same schedule", decoded, err)
+	}
+
+	// Changes are written back to a Store
+	if err := schedule.Set(time.Monday, DepartmentPharmacy, DayHours{Intervals: []Interval{{NewTimeOfDay(10, 0), NewTimeOfDay(14, 0)}}}); err != nil {
+		t.Fatalf("Set() ERROR: %q", err)
+	}
+	schedule.Apply(&storeData)
+	if storeData.RXHrsMon != "10:00am-2:00pm" || storeData.RXHrsTue != "9:00am-9:00pm" {
+		t.Errorf("Apply() RXHrsMon = %q, RXHrsTue = %q", storeData.RXHrsMon, storeData.RXHrsTue)
+	}
+
+	// The bad day is named
+	storeData.RXHrsWed = "all day"
+	schedule, err = NewWeeklySchedule(storeData)
+	if !errors.Is(err, ErrInvalidHours) || !strings.HasPrefix(err.Error(), "Wednesday pharmacy hours") {
+		t.Errorf("NewWeeklySchedule(<bad Wednesday>) ERROR = %v, want Wednesday pharmacy hours", err)
+	}
+
+	// The other days are still parsed
+	if schedule.Day(time.Wednesday, DepartmentPharmacy).State != HoursUnknown || schedule.Err(time.Wednesday, DepartmentPharmacy) == nil {
+		t.Errorf("NewWeeklySchedule(<bad Wednesday>) Wednesday = %+v, want unknown with an error", schedule.Day(time.Wednesday, DepartmentPharmacy))
+	}
+	if schedule.Day(time.Thursday, DepartmentPharmacy).String() != "9:00am-9:00pm" || schedule.Err(time.Thursday, DepartmentPharmacy) != nil {
+		t.Errorf("NewWeeklySchedule(<bad Wednesday>) Thursday = %s, want 9:00am-9:00pm", schedule.Day(time.Thursday, DepartmentPharmacy))
+	}
+}
+
+func TestWeeklyScheduleOutOfRange(t *testing.T) {
+	schedule, err := NewWeeklySchedule(scheduleStore())
+	if err != nil {
+		t.Fatalf("NewWeeklySchedule() ERROR: %q", err)
+	}
+	tests := []struct {
+		weekday time.Weekday
+		dept    Department
+	}{
+		{-1, DepartmentStore},
+		{7, DepartmentStore},
+		{time.Monday, -1},
+		{time.Monday, 2},
+	}
+	for _, test := range tests {
+		if day := schedule.Day(test.weekday, test.dept); day.State != HoursUnknown || day.Intervals != nil {
+			t.Errorf("Day(%d, %d) = %+v, want the zero DayHours", test.weekday, test.dept, day)
+		}
+		if err := schedule.Err(test.weekday, test.dept); !errors.Is(err, ErrInvalidDay) {
+			t.Errorf("Err(%d, %d) = %v, want %v", test.weekday, test.dept, err, ErrInvalidDay)
+		}
+		if err := schedule.Set(test.weekday, test.dept, DayHours{State: HoursClosed}); !errors.Is(err, ErrInvalidDay) {
+			t.Errorf("Set(%d, %d) ERROR = %v, want %v", test.weekday, test.dept, err, ErrInvalidDay)
+		}
+	}
+}
+
+func TestInvalidDayHours(t *testing.T) {
+	storeData := scheduleStore()
+	storeData.Latitude, storeData.Longitude = 41.0428, -82.7258
+	storeData.StoreHoursTuesday = "N/A"
+	loc, err := GetTZLocationLatLng(storeData.Location())
+	if err != nil {
+		t.Fatalf("GetTZLocationLatLng() ERROR: %q", err)
+	}
+
+	// Only Tuesday 2022-05-31 fails
+	if _, _, err := GetStoreHours("2022-05-31", storeData); !errors.Is(err, ErrInvalidHours) {
+		t.Errorf("GetStoreHours(<Tuesday>) ERROR = %v, want %v", err, ErrInvalidHours)
+	}
+	if storeHours, _, err := GetStoreHours("2022-06-01", storeData); err != nil || storeHours[0].Hour() != 8 {
+		t.Errorf("GetStoreHours(<Wednesday>) = %s, %v, want to open at 8am", storeHours, err)
+	}
+	if storeOpen, rxOpen, err := IsStoreOpen(time.Date(2022, 6, 1, 12, 0, 0, 0, loc), storeData); err != nil || !storeOpen || !rxOpen {
+		t.Errorf("IsStoreOpen(<Wednesday noon>) = %t, %t, %v, want open", storeOpen, rxOpen, err)
+	}
+	if _, _, err := IsStoreOpen(time.Date(2022, 5, 31, 12, 0, 0, 0, loc), storeData); !errors.Is(err, ErrInvalidHours) {
+		t.Errorf("IsStoreOpen(<Tuesday noon>) ERROR = %v, want %v", err, ErrInvalidHours)
+	}
+
+	// Searches forward skip it as unknown
+	if open, err := NextOpen(storeData, time.Date(2022, 5, 30, 23, 0, 0, 0, loc), DepartmentStore); err != nil || !open.Equal(time.Date(2022, 6, 1, 8, 0, 0, 0, loc)) {
+		t.Errorf("NextOpen(<Monday night>) = %s, %v, want Wednesday 8am", open, err)
+	}
+}
+
+func TestInvalidDayHoursDepartment(t *testing.T) {
+	storeData := scheduleStore()
+	storeData.Latitude, storeData.Longitude = 41.0428, -82.7258
+	storeData.RXHrsMon = "N/A"
+	loc, err := GetTZLocationLatLng(storeData.Location())
+	if err != nil {
+		t.Fatalf("GetTZLocationLatLng() ERROR: %q", err)
+	}
+	monday := time.Date(2022, 5, 30, 12, 0, 0, 0, loc)
+
+	// Only the pharmacy fails on Monday 2022-05-30
+	if open, err := NextOpen(storeData, monday.Add(-6*time.Hour), DepartmentStore); err != nil || !open.Equal(time.Date(2022, 5, 30, 8, 0, 0, 0, loc)) {
+		t.Errorf("NextOpen(<Monday>, DepartmentStore) = %s, %v, want Monday 8am", open, err)
+	}
+	if closes, err := NextClose(storeData, monday, DepartmentStore); err != nil || !closes.Equal(time.Date(2022, 5, 30, 22, 0, 0, 0, loc)) {
+		t.Errorf("NextClose(<Monday>, DepartmentStore) = %s, %v, want Monday 10pm", closes, err)
+	}
+	if status, err := GetOpenStatus(storeData, monday, DepartmentStore); err != nil || status.State != StateOpen {
+		t.Errorf("GetOpenStatus(<Monday>, DepartmentStore) = %+v, %v, want open", status, err)
+	}
+	if _, err := GetOpenStatus(storeData, monday, DepartmentPharmacy); !errors.Is(err, ErrInvalidHours) {
+		t.Errorf("GetOpenStatus(<Monday>, DepartmentPharmacy) ERROR = %v, want %v", err, ErrInvalidHours)
+	}
+
+	// Both department lookups keep the valid store hours
+	if storeOpen, _, err := IsStoreOpen(monday, storeData); !errors.Is(err, ErrInvalidHours) || !storeOpen {
+		t.Errorf("IsStoreOpen(<Monday>) = %t, %v, want store open with %v", storeOpen, err, ErrInvalidHours)
+	}
+	if storeHours, _, err := GetStoreHours("2022-05-30", storeData); !errors.Is(err, ErrInvalidHours) || storeHours[0].Hour() != 8 {
+		t.Errorf("GetStoreHours(<Monday>) = %s, %v, want to open at 8am with %v", storeHours, err, ErrInvalidHours)
+	}
+	if storeDay, rxDay, err := GetStoreDayHours("2022-05-30", storeData); !errors.Is(err, ErrInvalidHours) || storeDay.State != HoursOpen || rxDay.State != HoursUnknown {
+		t.Errorf("GetStoreDayHours(<Monday>) = %s, %s, %v, want store open and pharmacy unknown", storeDay, rxDay, err)
+	}
+}
+
+func TestHoursStates(t *testing.T) {
+	tests := []struct {
+		raw   string
+		state HoursState
+	}{
+		{"", HoursUnknown},
+		{"  ", HoursUnknown},
+		{"Closed", HoursClosed},
+		{"CLOSED ALL DAY", HoursClosed},
+		{"Open 24 Hours", HoursOpen24},
+		{"24 hrs", HoursOpen24},
+		{"24/7", HoursOpen24},
+		{"9:00am-5:00pm", HoursOpen},
+	}
+	for _, test := range tests {
+		hours, err := ParseDayHours(test.raw)
+		if err != nil || hours.State != test.state {
+			t.Errorf("ParseDayHours(%q) = %s, %v, want %s", test.raw, hours.State, err, test.state)
+		}
+	}
+
+	if got := (DayHours{State: HoursClosed}).String(); got != "Closed" {
+		t.Errorf("DayHours{<closed>}.String() = %q, want %q", got, "Closed")
+	}
+}
+
+func TestStoreHoursStates(t *testing.T) {
+	storeData := scheduleStore()
+	storeData.Latitude, storeData.Longitude = 41.0428, -82.7258
+	storeData.StoreHoursSunday = "Open 24 Hours"
+	storeData.RXHrsSun = "Closed"
+	storeData.RXHrsSat = ""
+	storeData.HolidayHours = []HolidayHours{{HolidayDate: "2022-12-26", StoreHours: "10:00am-6:00pm", PharmacyHours: "Closed"}}
+	loc, err := GetTZLocationLatLng(storeData.Location())
+	if err != nil {
+		t.Fatalf("GetTZLocationLatLng() ERROR: %q", err)
+	}
+
+	// Sunday 2022-05-29: the store never closes, the pharmacy is closed
+	storeHours, rxHours, err := GetStoreHours("2022-05-29", storeData)
+	if err != nil {
+		t.Fatalf("GetStoreHours(<Sunday>) ERROR: %q", err)
+	}
+	if !storeHours[0].Equal(time.Date(2022, 5, 29, 0, 0, 0, 0, loc)) || !storeHours[1].Equal(time.Date(2022, 5, 30, 0, 0, 0, 0, loc)) {
+		t.Errorf("GetStoreHours(<Sunday>) store = %s, want midnight to midnight", storeHours)
+	}
+	if !rxHours[0].IsZero() || !rxHours[1].IsZero() {
+		t.Errorf("GetStoreHours(<Sunday>) rx = %s, want zero times", rxHours)
+	}
+
+	storeOpen, rxOpen, err := IsStoreOpen(time.Date(2022, 5, 29, 3, 0, 0, 0, loc), storeData)
+	if err != nil || !storeOpen || rxOpen {
+		t.Errorf("IsStoreOpen(<Sunday 3am>) = %t, %t, %v, want true, false", storeOpen, rxOpen, err)
+	}
+
+	// Saturday 2022-05-28: no pharmacy hours data
+	storeDay, rxDay, err := GetStoreDayHours("2022-05-28", storeData)
+	if err != nil || storeDay.State != HoursOpen || rxDay.State != HoursUnknown {
+		t.Errorf("GetStoreDayHours(<Saturday>) = %s, %s, %v, want open, unknown", storeDay.State, rxDay.State, err)
+	}
+	storeOpen, rxOpen, err = IsStoreOpen(time.Date(2022, 5, 28, 12, 0, 0, 0, loc), storeData)
+	if err != nil || !storeOpen || rxOpen {
+		t.Errorf("IsStoreOpen(<Saturday noon>) = %t, %t, %v, want true, false", storeOpen, rxOpen, err)
+	}
+
+	// Holiday hours can close a department too
+	storeDay, rxDay, err = GetStoreDayHours("2022-12-26", storeData)
+	if err != nil || storeDay.String() != "10:00am-6:00pm" || rxDay.State != HoursClosed {
+		t.Errorf("GetStoreDayHours(<holiday>) = %s, %s, %v, want 10:00am-6:00pm, closed", storeDay, rxDay.State, err)
+	}
+}
+
+func TestOvernightHours(t *testing.T) {
+	storeData := scheduleStore()
+	storeData.Latitude, storeData.Longitude = 41.0428, -82.7258
+	storeData.StoreHoursFriday = "7:00am-1:00am"
+	storeData.StoreHoursSaturday = "Closed"
+	loc, err := GetTZLocationLatLng(storeData.Location())
+	if err != nil {
+		t.Fatalf("GetTZLocationLatLng() ERROR: %q", err)
+	}
+
+	// Friday 2022-05-27 closes at 1am on Saturday
+	storeHours, _, err := GetStoreHours("2022-05-27", storeData)
+	if err != nil || !storeHours[1].Equal(time.Date(2022, 5, 28, 1, 0, 0, 0, loc)) {
+		t.Errorf("GetStoreHours(<Friday>) store = %s, %v, want to close 1am Saturday", storeHours, err)
+	}
+
+	tests := []struct {
+		at   time.Time
+		open bool
+	}{
+		{time.Date(2022, 5, 27, 23, 0, 0, 0, loc), true},
+		{time.Date(2022, 5, 28, 0, 30, 0, 0, loc), true},
+		{time.Date(2022, 5, 28, 1, 30, 0, 0, loc), false},
+		{time.Date(2022, 5, 28, 12, 0, 0, 0, loc), false},
+		{time.Date(2022, 5, 27, 0, 30, 0, 0, loc), false},
+
+		// Open at opening time, closed at closing time
+		{time.Date(2022, 5, 27, 7, 0, 0, 0, loc), true},
+		{time.Date(2022, 5, 28, 1, 0, 0, 0, loc), false},
+	}
+	for _, test := range tests {
+		storeOpen, _, err := IsStoreOpen(test.at, storeData)
+		if err != nil || storeOpen != test.open {
+			t.Errorf("IsStoreOpen(%s) = %t, %v, want %t", test.at, storeOpen, err, test.open)
+		}
+	}
+}
+
+func TestSplitHours(t *testing.T) {
+	hours, err := ParseDayHours("2:00pm-9:00pm; 9:00am-1:30pm")
+	if err != nil || len(hours.Intervals) != 2 || hours.Intervals[0].String() != "9:00am-1:30pm" || hours.Intervals[1].String() != "2:00pm-9:00pm" {
+		t.Fatalf("ParseDayHours(<lunch break>) = %+v, %v, want two sorted intervals", hours, err)
+	}
+	if got := (DayHours{Intervals: hours.Intervals}).String(); got != "9:00am-1:30pm, 2:00pm-9:00pm" {
+		t.Errorf("DayHours.String(<lunch break>) = %q", got)
+	}
+	if _, err := ParseDayHours("9:00am-2:00pm, 1:00pm-9:00pm"); !errors.Is(err, ErrInvalidHours) {
+		t.Errorf("ParseDayHours(<overlapping>) ERROR = %v, want %v", err, ErrInvalidHours)
+	}
+
+	storeData := scheduleStore()
+	storeData.Latitude, storeData.Longitude = 41.0428, -82.7258
+	storeData.RXHrsMon = "9:00am-1:30pm, 2:00pm-9:00pm"
+	loc, err := GetTZLocationLatLng(storeData.Location())
+	if err != nil {
+		t.Fatalf("GetTZLocationLatLng() ERROR: %q", err)
+	}
+
+	// Monday 2022-05-30
+	storeIntervals, rxIntervals, err := GetStoreIntervals("2022-05-30", storeData)
+	if err != nil || len(storeIntervals) != 1 || len(rxIntervals) != 2 {
+		t.Fatalf("GetStoreIntervals() = %v, %v, %v, want 1 and 2 intervals", storeIntervals, rxIntervals, err)
+	}
+	if !rxIntervals[0][1].Equal(time.Date(2022, 5, 30, 13, 30, 0, 0, loc)) || !rxIntervals[1][0].Equal(time.Date(2022, 5, 30, 14, 0, 0, 0, loc)) {
+		t.Errorf("GetStoreIntervals() rx = %v, want a break 1:30pm to 2:00pm", rxIntervals)
+	}
+
+	tests := []struct {
+		at   time.Time
+		open bool
+	}{
+		{time.Date(2022, 5, 30, 11, 0, 0, 0, loc), true},
+		{time.Date(2022, 5, 30, 13, 45, 0, 0, loc), false},
+		{time.Date(2022, 5, 30, 15, 0, 0, 0, loc), true},
+	}
+	for _, test := range tests {
+		storeOpen, rxOpen, err := IsStoreOpen(test.at, storeData)
+		if err != nil || !storeOpen || rxOpen != test.open {
+			t.Errorf("IsStoreOpen(%s) = %t, %t, %v, want true, %t", test.at, storeOpen, rxOpen, err, test.open)
+		}
+	}
+}
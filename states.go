@@ -0,0 +1,66 @@
+package riteaid
+
+import "strings"
+
+// Approximate bounding boxes of the US states and DC. These are only used to
+// plan sweeps so they are deliberately generous rather than exact.
+var stateBounds = map[string]BoundingBox{
+	"AL": {LatLng{30.14, -88.47}, LatLng{35.01, -84.89}},
+	"AK": {LatLng{51.20, -179.90}, LatLng{71.40, -129.90}},
+	"AZ": {LatLng{31.33, -114.82}, LatLng{37.00, -109.05}},
+	"AR": {LatLng{33.00, -94.62}, LatLng{36.50, -89.64}},
+	"CA": {LatLng{32.53, -124.41}, LatLng{42.01, -114.13}},
+	"CO": {LatLng{36.99, -109.06}, LatLng{41.00, -102.04}},
+	"CT": {LatLng{40.95, -73.73}, LatLng{42.05, -71.79}},
+	"DC": {LatLng{38.79, -77.12}, LatLng{39.00, -76.91}},
+	"DE": {LatLng{38.45, -75.79}, LatLng{39.84, -75.05}},
+	"FL": {LatLng{24.52, -87.63}, LatLng{31.00, -80.03}},
+	"GA": {LatLng{30.36, -85.61}, LatLng{35.00, -80.84}},
+	"HI": {LatLng{18.91, -160.25}, LatLng{22.24, -154.81}},
+	"IA": {LatLng{40.38, -96.64}, LatLng{43.50, -90.14}},
+	"ID": {LatLng{41.99, -117.24}, LatLng{49.00, -111.04}},
+	"IL": {LatLng{36.97, -91.51}, LatLng{42.51, -87.49}},
+	"IN": {LatLng{37.77, -88.10}, LatLng{41.76, -84.78}},
+	"KS": {LatLng{36.99, -102.05}, LatLng{40.00, -94.59}},
+	"KY": {LatLng{36.50, -89.57}, LatLng{39.15, -81.96}},
+	"LA": {LatLng{28.93, -94.04}, LatLng{33.02, -88.82}},
+	"MA": {LatLng{41.24, -73.51}, LatLng{42.89, -69.93}},
+	"MD": {LatLng{37.91, -79.49}, LatLng{39.72, -75.05}},
+	"ME": {LatLng{43.06, -71.08}, LatLng{47.46, -66.95}},
+	"MI": {LatLng{41.70, -90.42}, LatLng{48.31, -82.41}},
+	"MN": {LatLng{43.50, -97.24}, LatLng{49.38, -89.49}},
+	"MO": {LatLng{35.99, -95.77}, LatLng{40.61, -89.10}},
+	"MS": {LatLng{30.17, -91.66}, LatLng{35.00, -88.10}},
+	"MT": {LatLng{44.36, -116.05}, LatLng{49.00, -104.04}},
+	"NC": {LatLng{33.84, -84.32}, LatLng{36.59, -75.46}},
+	"ND": {LatLng{45.94, -104.05}, LatLng{49.00, -96.55}},
+	"NE": {LatLng{40.00, -104.05}, LatLng{43.00, -95.31}},
+	"NH": {LatLng{42.70, -72.56}, LatLng{45.31, -70.61}},
+	"NJ": {LatLng{38.93, -75.56}, LatLng{41.36, -73.89}},
+	"NM": {LatLng{31.33, -109.05}, LatLng{37.00, -103.00}},
+	"NV": {LatLng{35.00, -120.01}, LatLng{42.00, -114.04}},
+	"NY": {LatLng{40.50, -79.76}, LatLng{45.02, -71.86}},
+	"OH": {LatLng{38.40, -84.82}, LatLng{41.98, -80.52}},
+	"OK": {LatLng{33.62, -103.00}, LatLng{37.00, -94.43}},
+	"OR": {LatLng{41.99, -124.57}, LatLng{46.29, -116.46}},
+	"PA": {LatLng{39.72, -80.52}, LatLng{42.27, -74.69}},
+	"RI": {LatLng{41.15, -71.91}, LatLng{42.02, -71.12}},
+	"SC": {LatLng{32.03, -83.35}, LatLng{35.22, -78.54}},
+	"SD": {LatLng{42.48, -104.06}, LatLng{45.95, -96.44}},
+	"TN": {LatLng{34.98, -90.31}, LatLng{36.68, -81.65}},
+	"TX": {LatLng{25.84, -106.65}, LatLng{36.50, -93.51}},
+	"UT": {LatLng{37.00, -114.05}, LatLng{42.00, -109.04}},
+	"VA": {LatLng{36.54, -83.68}, LatLng{39.47, -75.24}},
+	"VT": {LatLng{42.73, -73.44}, LatLng{45.02, -71.46}},
+	"WA": {LatLng{45.54, -124.85}, LatLng{49.00, -116.92}},
+	"WI": {LatLng{42.49, -92.89}, LatLng{47.31, -86.25}},
+	"WV": {LatLng{37.20, -82.64}, LatLng{40.64, -77.72}},
+	"WY": {LatLng{40.99, -111.06}, LatLng{45.01, -104.05}},
+}
+
+// StateBoundingBox returns the approximate bounding box of a US state given
+// its two letter postal code, i.e. "OH".
+func StateBoundingBox(state string) (BoundingBox, bool) {
+	box, ok := stateBounds[strings.ToUpper(strings.TrimSpace(state))]
+	return box, ok
+}
@@ -0,0 +1,219 @@
+package riteaid
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// How long before closing GetOpenStatus reports StateClosingSoon by default
+const DefaultClosingSoon = 30 * time.Minute
+
+// OpenState is the state of a department at a point in time.
+type OpenState int
+
+const (
+	// No hours data for the day and not open from the day before
+	StateUnknown OpenState = iota
+
+	// Not open at the time
+	StateClosed
+
+	// Open at the time
+	StateOpen
+
+	// Open at the time and closing within the ClosingSoon window
+	StateClosingSoon
+
+	// Open 24 hours on the day
+	StateOpen24
+)
+
+// String returns "unknown", "closed", "open", "closing soon" or "open 24
+// hours".
+func (s OpenState) String() string {
+	switch s {
+	case StateUnknown:
+		return "unknown"
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateClosingSoon:
+		return "closing soon"
+	case StateOpen24:
+		return "open 24 hours"
+	}
+	return fmt.Sprintf("OpenState(%d)", int(s))
+}
+
+// Open returns true for StateOpen, StateClosingSoon and StateOpen24.
+func (s OpenState) Open() bool {
+	return s == StateOpen || s == StateClosingSoon || s == StateOpen24
+}
+
+// OpenStatus describes whether a department of a store is open at a point
+// in time, and why.
+type OpenStatus struct {
+	Department Department
+	State      OpenState
+
+	// The time asked about, in the store's time zone
+	At time.Time
+
+	// The hours of the day in effect. While open these are the hours of the
+	// interval's day, which is the previous day for overnight hours.
+	Hours DayHours
+
+	// Where Hours come from: regular or holiday hours
+	Source HoursSource
+
+	// The special prescription pickup hours the pharmacy lists for the day
+	// of At, with Source SourceSpecial. They are reported as listed and do
+	// not change State, which follows Hours. HoursUnknown when none are
+	// listed, the department is the store or they cannot be parsed.
+	Pickup DayHours
+
+	// The interval open at the time and its instants, zero when closed
+	Interval Interval
+	Start    time.Time
+	End      time.Time
+
+	// When open, the time it closes, zero when it does not close within
+	// the days searched. Intervals that meet count as one opening, so this
+	// may be later than End.
+	ClosesAt time.Time
+
+	// When closed, the time it next opens, zero when it does not open
+	// within the days searched
+	OpensAt time.Time
+}
+
+// String returns a one line description of the status, i.e. "pharmacy open
+// until 9:00pm" or "store closed, opens Tue 8:00am".
+func (s OpenStatus) String() string {
+	switch {
+	case s.State.Open() && !s.ClosesAt.IsZero():
+		return fmt.Sprintf("%s %s until %s", s.Department, s.State, s.ClosesAt.Format("3:04pm"))
+	case !s.State.Open() && !s.OpensAt.IsZero():
+		return fmt.Sprintf("%s %s, opens %s", s.Department, s.State, s.OpensAt.Format("Mon 3:04pm"))
+	}
+	return fmt.Sprintf("%s %s", s.Department, s.State)
+}
+
+// GetOpenStatus returns the OpenStatus of a department of the store at t.
+// Holiday hours, overnight hours and breaks are all taken into account.
+// Special pickup hours of the pharmacy are reported in Pickup.
+//
+// Opening times are inclusive and closing times exclusive: a store open
+// "8:00am-10:00pm" is open from 8:00am up to but not including 10:00pm, so
+// at 10:00pm it reads as closed. Back to back intervals never leave a gap
+// and a day open 24 hours is open at every instant of it. A store is
+// StateClosingSoon when ClosesAt is at most ClosingSoon after t, whatever
+// the hours of the day.
+//
+//	status, err := GetOpenStatus(storeData, time.Now(), DepartmentPharmacy)
+//	if err != nil {
+//		panic(err)
+//	}
+//	fmt.Println(status) // "pharmacy closing soon until 9:00pm"
+//
+// This is a thin wrapper around DefaultClient.Hours().GetOpenStatus.
+func GetOpenStatus(storeData Store, t time.Time, dept Department) (OpenStatus, error) {
+	return DefaultClient.Hours().GetOpenStatus(storeData, t, dept)
+}
+
+// GetOpenStatus is GetOpenStatus in the location and under the DSTPolicy of
+// the Hours.
+func (h Hours) GetOpenStatus(storeData Store, t time.Time, dept Department) (OpenStatus, error) {
+	loc, schedule, err := h.prepare(storeData)
+	if err != nil {
+		return OpenStatus{}, err
+	}
+	t = t.In(loc)
+	status := OpenStatus{Department: dept, At: t, Pickup: DayHours{Source: SourceSpecial}}
+	if dept == DepartmentPharmacy {
+		status.Pickup = pickupHours(storeData, t.Format(DateFormat))
+	}
+
+	// The day of t first, then overnight hours of the day before
+	for _, offset := range []int{0, -1} {
+		day, hours, err := dayHoursOn(t.AddDate(0, 0, offset).Format(DateFormat), dept, loc, storeData, schedule)
+		if offset != 0 {
+			err = adjacentErr(err)
+		}
+		if err != nil {
+			return OpenStatus{}, err
+		}
+		if offset == 0 {
+			status.Hours, status.Source = hours, hours.Source
+		}
+		for _, interval := range hours.Intervals {
+			start, end, err := h.DST.interval(interval, day)
+			if err != nil {
+				return OpenStatus{}, err
+			}
+			if !t.Before(start) && t.Before(end) {
+				status.Hours, status.Source = hours, hours.Source
+				status.Interval, status.Start, status.End = interval, start, end
+				return h.openStatus(status, storeData)
+			}
+		}
+	}
+	return h.closedStatus(status, storeData)
+}
+
+// Private method completing the status of a department open at its time.
+func (h Hours) openStatus(status OpenStatus, storeData Store) (OpenStatus, error) {
+	status.State = StateOpen
+	if status.Hours.State == HoursOpen24 {
+		status.State = StateOpen24
+	}
+	closes, err := h.NextClose(storeData, status.At, status.Department)
+	if errors.Is(err, ErrNoHoursFound) {
+		return status, nil
+	}
+	if err != nil {
+		return OpenStatus{}, err
+	}
+	status.ClosesAt = closes
+	if closes.Sub(status.At) <= h.closingSoon() {
+		status.State = StateClosingSoon
+	}
+	return status, nil
+}
+
+// Private method completing the status of a department closed at its time.
+func (h Hours) closedStatus(status OpenStatus, storeData Store) (OpenStatus, error) {
+	status.State = StateClosed
+	if status.Hours.State == HoursUnknown {
+		status.State = StateUnknown
+	}
+	opens, err := h.NextOpen(storeData, status.At, status.Department)
+	if errors.Is(err, ErrNoHoursFound) {
+		return status, nil
+	}
+	if err != nil {
+		return OpenStatus{}, err
+	}
+	status.OpensAt = opens
+	return status, nil
+}
+
+// Private function returning the special pickup hours of the pharmacy on
+// date, HoursUnknown when none are listed or they cannot be parsed.
+func pickupHours(storeData Store, date string) DayHours {
+	// Unparseable hours come back as HoursUnknown with their Raw string
+	day, _ := ParseDayHours(storeData.PickupDateAndTimes.SpecialHours[date])
+	day.Source = SourceSpecial
+	return day
+}
+
+// Private method returning the window before closing reported as
+// StateClosingSoon.
+func (h Hours) closingSoon() time.Duration {
+	if h.ClosingSoon <= 0 {
+		return DefaultClosingSoon
+	}
+	return h.ClosingSoon
+}
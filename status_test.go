@@ -0,0 +1,118 @@
+package riteaid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetOpenStatus(t *testing.T) {
+	storeData := scheduleStore()
+	storeData.Latitude, storeData.Longitude = 41.0428, -82.7258
+	storeData.StoreHoursFriday = "7:00am-1:00am"
+	storeData.StoreHoursSaturday = "Open 24 Hours"
+	storeData.StoreHoursSunday = "8:00am-2:00am"
+	storeData.RXHrsSun = ""
+	storeData.RXHrsThu = "9:00am-1:30pm, 2:00pm-9:00pm"
+	storeData.HolidayHours = []HolidayHours{{HolidayDate: "2022-05-30", StoreHours: "10:00am-6:00pm", PharmacyHours: "Closed"}}
+	storeData.PickupDateAndTimes.SpecialHours = map[string]string{"2022-05-31": "10:00 AM-4:00 PM"}
+	loc, err := GetTZLocationLatLng(storeData.Location())
+	if err != nil {
+		t.Fatalf("GetTZLocationLatLng() ERROR: %q", err)
+	}
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2022, 5, day, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name     string
+		dept     Department
+		t        time.Time
+		state    OpenState
+		source   HoursSource
+		interval string
+		closes   time.Time
+		opens    time.Time
+	}{
+		{"at opening", DepartmentStore, at(26, 8, 0), StateOpen, SourceRegular, "8:00am-10:00pm", at(26, 22, 0), time.Time{}},
+		{"closing soon", DepartmentStore, at(26, 21, 30), StateClosingSoon, SourceRegular, "8:00am-10:00pm", at(26, 22, 0), time.Time{}},
+		{"at closing", DepartmentStore, at(26, 22, 0), StateClosed, SourceRegular, "", time.Time{}, at(27, 7, 0)},
+		{"lunch break", DepartmentPharmacy, at(26, 13, 45), StateClosed, SourceRegular, "", time.Time{}, at(26, 14, 0)},
+		{"overnight into 24 hours", DepartmentStore, at(28, 0, 30), StateOpen24, SourceRegular, "12:00am-12:00am", at(29, 0, 0), time.Time{}},
+		{"overnight into holiday", DepartmentStore, at(30, 1, 0), StateOpen, SourceRegular, "8:00am-2:00am", at(30, 2, 0), time.Time{}},
+		{"after overnight", DepartmentStore, at(30, 2, 0), StateClosed, SourceHoliday, "", time.Time{}, at(30, 10, 0)},
+		{"24 hours", DepartmentStore, at(28, 12, 0), StateOpen24, SourceRegular, "12:00am-12:00am", at(29, 0, 0), time.Time{}},
+		{"unknown", DepartmentPharmacy, at(29, 12, 0), StateUnknown, SourceRegular, "", time.Time{}, at(31, 9, 0)},
+		{"holiday", DepartmentStore, at(30, 12, 0), StateOpen, SourceHoliday, "10:00am-6:00pm", at(30, 18, 0), time.Time{}},
+		{"holiday closed", DepartmentPharmacy, at(30, 12, 0), StateClosed, SourceHoliday, "", time.Time{}, at(31, 9, 0)},
+		{"special pickup", DepartmentPharmacy, at(31, 16, 30), StateOpen, SourceRegular, "9:00am-9:00pm", at(31, 21, 0), time.Time{}},
+	}
+	for _, test := range tests {
+		status, err := GetOpenStatus(storeData, test.t, test.dept)
+		if err != nil {
+			t.Errorf("%s: GetOpenStatus() ERROR: %q", test.name, err)
+			continue
+		}
+		if status.State != test.state || status.Source != test.source || status.Department != test.dept {
+			t.Errorf("%s: GetOpenStatus() = %s from %s hours, want %s from %s hours", test.name, status.State, status.Source, test.state, test.source)
+		}
+		if interval := status.Interval.String(); test.interval != "" && interval != test.interval || test.interval == "" && !status.Start.IsZero() {
+			t.Errorf("%s: GetOpenStatus() interval = %s, want %q", test.name, interval, test.interval)
+		}
+		if !status.ClosesAt.Equal(test.closes) || !status.OpensAt.Equal(test.opens) {
+			t.Errorf("%s: GetOpenStatus() closes %s, opens %s, want %s, %s", test.name, status.ClosesAt, status.OpensAt, test.closes, test.opens)
+		}
+	}
+
+	// Special pickup hours are reported beside the pharmacy hours
+	status, err := GetOpenStatus(storeData, at(31, 16, 30), DepartmentPharmacy)
+	if err != nil || status.Pickup.Source != SourceSpecial || status.Pickup.State != HoursOpen || status.Pickup.Intervals[0].String() != "10:00am-4:00pm" {
+		t.Errorf("GetOpenStatus(<special>).Pickup = %+v, %v", status.Pickup, err)
+	}
+	for _, dept := range []Department{DepartmentStore, DepartmentPharmacy} {
+		status, err = GetOpenStatus(storeData, at(26, 12, 0), dept)
+		if err != nil || status.Pickup.State != HoursUnknown || status.Pickup.Source != SourceSpecial {
+			t.Errorf("GetOpenStatus(<%s, no special>).Pickup = %+v, %v", dept, status.Pickup, err)
+		}
+	}
+
+	// The window is configurable
+	status, err = (Hours{ClosingSoon: time.Hour}).GetOpenStatus(storeData, at(26, 21, 0), DepartmentStore)
+	if err != nil || status.State != StateClosingSoon {
+		t.Errorf("Hours{ClosingSoon: 1h}.GetOpenStatus(<9pm>) = %s, %v, want %s", status.State, err, StateClosingSoon)
+	}
+	if got := status.String(); got != "store closing soon until 10:00pm" {
+		t.Errorf("OpenStatus.String() = %q", got)
+	}
+	status, err = GetOpenStatus(storeData, at(26, 22, 0), DepartmentStore)
+	if got := status.String(); err != nil || got != "store closed, opens Fri 7:00am" {
+		t.Errorf("OpenStatus.String() = %q, %v", got, err)
+	}
+}
+
+func TestSpecialHoursKeepPharmacyHours(t *testing.T) {
+	storeData := scheduleStore()
+	storeData.Latitude, storeData.Longitude = 41.0428, -82.7258
+	storeData.RXHrsMon = "9:00am-1:30pm, 2:00pm-9:00pm"
+	storeData.PickupDateAndTimes.SpecialHours = map[string]string{"2022-05-30": "1:00 PM-5:00 PM"}
+	loc, err := GetTZLocationLatLng(storeData.Location())
+	if err != nil {
+		t.Fatalf("GetTZLocationLatLng() ERROR: %q", err)
+	}
+	at := time.Date(2022, 5, 30, 9, 30, 0, 0, loc)
+
+	if store, rx, err := IsStoreOpen(at, storeData); err != nil || !store || !rx {
+		t.Errorf("IsStoreOpen(%s) = [%v,%v], %v, want [true,true]", at, store, rx, err)
+	}
+	_, rxHours, err := GetStoreHours("2022-05-30", storeData)
+	if err != nil || rxHours[0].Hour() != 9 || rxHours[1].Hour() != 21 {
+		t.Errorf("GetStoreHours() pharmacy = %v, %v, want 9:00am-9:00pm", rxHours, err)
+	}
+	_, rxDay, err := GetStoreDayHours("2022-05-30", storeData)
+	if err != nil || rxDay.Source != SourceRegular || len(rxDay.Intervals) != 2 {
+		t.Errorf("GetStoreDayHours() pharmacy = %+v, %v, want the regular Monday hours", rxDay, err)
+	}
+	status, err := GetOpenStatus(storeData, at, DepartmentPharmacy)
+	if err != nil || status.State != StateOpen || status.Source != SourceRegular || status.Pickup.Raw != "1:00 PM-5:00 PM" {
+		t.Errorf("GetOpenStatus() = %+v, %v", status, err)
+	}
+}
@@ -0,0 +1,347 @@
+package riteaid
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// Default radius in miles of every search made by a sweep
+	DefaultSweepRadius = 25
+
+	// Default number of stores at which a tile is considered dense and is
+	// searched again as seven smaller tiles
+	DefaultDenseThreshold = 25
+
+	// Default number of times a tile may be subdivided
+	DefaultSweepMaxDepth = 3
+
+	// Fraction of the largest cell a circle covers that is actually used,
+	// the rest overlaps the neighbouring circles to absorb the error of the
+	// flat earth math and of the API's own distance calculation
+	sweepCellFactor = 0.9
+)
+
+// Error returned when a state code is not known to StateBoundingBox
+var ErrUnknownState = errors.New("unknown state code")
+
+// Error returned when a sweep checkpoint file belongs to a different sweep
+var ErrCheckpointMismatch = errors.New("checkpoint belongs to a different sweep")
+
+// SweepOptions controls a sweep. The zero value is usable.
+type SweepOptions struct {
+	// Radius in miles of each search. Defaults to DefaultSweepRadius.
+	Radius float64
+
+	// Tiles returning at least this many stores are subdivided.
+	// Defaults to DefaultDenseThreshold.
+	DenseThreshold int
+
+	// Maximum number of times a tile is subdivided.
+	// Defaults to DefaultSweepMaxDepth, use a negative value to never subdivide.
+	MaxDepth int
+
+	// Optional file the progress is saved to after every search. When the
+	// file exists the sweep resumes from it. Delete it to start over.
+	Checkpoint string
+
+	// Optional function deciding which stores are kept.
+	Filter func(store Store) bool
+
+	// Optional hook called after every search.
+	OnProgress func(progress SweepProgress)
+}
+
+// SweepTile is a single search planned by a sweep.
+type SweepTile struct {
+	Center LatLng  `json:"center"`
+	Radius float64 `json:"radius"`
+	Depth  int     `json:"depth"`
+}
+
+// SweepProgress is passed to SweepOptions.OnProgress after every search.
+type SweepProgress struct {
+	Tile       SweepTile // The tile just searched
+	Found      int       // Stores returned by the search
+	New        int       // Stores not seen before in this sweep
+	Subdivided bool      // The tile was dense and has been split
+	Searches   int       // Searches completed so far
+	Pending    int       // Tiles left to search
+	Stores     int       // Unique stores found so far
+}
+
+// SweepResult is the outcome of a sweep.
+type SweepResult struct {
+	Stores   []Store // Unique stores sorted by StoreNumber
+	Searches int     // Number of searches made, including those before a resume
+}
+
+// Private type saved to the checkpoint file
+type sweepState struct {
+	Area     string      `json:"area"`
+	Pending  []SweepTile `json:"pending"`
+	Stores   []Store     `json:"stores"`
+	Searches int         `json:"searches"`
+}
+
+// SweepBox runs Client.SweepBox using DefaultClient.
+func SweepBox(ctx context.Context, box BoundingBox, opts SweepOptions) (SweepResult, error) {
+	return DefaultClient.SweepBox(ctx, box, opts)
+}
+
+// SweepState runs Client.SweepState using DefaultClient.
+func SweepState(ctx context.Context, state string, opts SweepOptions) (SweepResult, error) {
+	return DefaultClient.SweepState(ctx, state, opts)
+}
+
+// SweepPoints runs Client.SweepPoints using DefaultClient.
+func SweepPoints(ctx context.Context, points []LatLng, opts SweepOptions) (SweepResult, error) {
+	return DefaultClient.SweepPoints(ctx, points, opts)
+}
+
+// SweepBox finds every store in a bounding box by covering it with
+// overlapping searches. See SweepPoints for details.
+//
+//	box := riteaid.BoundingBox{
+//		SouthWest: riteaid.LatLng{Latitude: 40.9, Longitude: -83.0},
+//		NorthEast: riteaid.LatLng{Latitude: 41.4, Longitude: -82.3},
+//	}
+//	result, err := client.SweepBox(ctx, box, riteaid.SweepOptions{Checkpoint: "sweep.json"})
+func (c *Client) SweepBox(ctx context.Context, box BoundingBox, opts SweepOptions) (SweepResult, error) {
+	opts = opts.withDefaults()
+	area := fmt.Sprintf("box:%s:%s:%s", box.SouthWest, box.NorthEast, opts.key())
+	return c.sweep(ctx, area, tileBox(box, opts.Radius), opts)
+}
+
+// SweepState finds every store in a US state given its two letter postal
+// code, i.e. "OH". Unless opts.Filter is set only stores in that state are
+// kept. See SweepPoints for details.
+func (c *Client) SweepState(ctx context.Context, state string, opts SweepOptions) (SweepResult, error) {
+	box, ok := StateBoundingBox(state)
+	if !ok {
+		return SweepResult{}, fmt.Errorf("%w: %q", ErrUnknownState, state)
+	}
+
+	opts = opts.withDefaults()
+	if opts.Filter == nil {
+		opts.Filter = func(store Store) bool {
+			return strings.EqualFold(store.State, strings.TrimSpace(state))
+		}
+	}
+	area := fmt.Sprintf("state:%s:%s", strings.ToUpper(strings.TrimSpace(state)), opts.key())
+	return c.sweep(ctx, area, tileBox(box, opts.Radius), opts)
+}
+
+// SweepPoints finds the stores around a list of points, i.e. ZIP code
+// centroids, searching opts.Radius miles around each of them.
+//
+// Stores found by more than one search are kept once, matched by
+// StoreNumber. A search returning opts.DenseThreshold stores or more is
+// repeated as seven searches of a little over half the radius, one in the
+// middle and six around it, covering the whole circle, so results capped
+// by the API do not hide stores.
+//
+// When opts.Checkpoint is set the progress is saved after every search. If
+// the sweep is interrupted (an error or ctx is canceled) calling it again
+// with the same arguments resumes where it stopped.
+//
+// Every search is made with a "latitude,longitude" address, which is not
+// verified to be geocoded as such, see LatLng.String.
+func (c *Client) SweepPoints(ctx context.Context, points []LatLng, opts SweepOptions) (SweepResult, error) {
+	opts = opts.withDefaults()
+
+	tiles := make([]SweepTile, len(points))
+	hash := sha256.New()
+	for i, p := range points {
+		tiles[i] = SweepTile{Center: p, Radius: opts.Radius}
+		fmt.Fprintf(hash, "%s;", p)
+	}
+	area := fmt.Sprintf("points:%s:%s", hex.EncodeToString(hash.Sum(nil)), opts.key())
+	return c.sweep(ctx, area, tiles, opts)
+}
+
+// Private method running a sweep over the planned tiles.
+func (c *Client) sweep(ctx context.Context, area string, tiles []SweepTile, opts SweepOptions) (SweepResult, error) {
+	state, err := loadSweepState(opts.Checkpoint, area, tiles)
+	if err != nil {
+		return SweepResult{}, err
+	}
+
+	seen := make(map[uint32]bool, len(state.Stores))
+	for _, store := range state.Stores {
+		seen[store.StoreNumber] = true
+	}
+
+	for len(state.Pending) > 0 {
+		tile := state.Pending[0]
+		result, err := c.SearchContext(ctx, tile.Center.String(), tile.Radius)
+		if err != nil {
+			return state.result(), err
+		}
+		state.Pending = state.Pending[1:]
+		state.Searches++
+
+		// Dense tiles are searched again in smaller pieces
+		subdivided := len(result.Data.Stores) >= opts.DenseThreshold && tile.Depth < opts.MaxDepth
+		if subdivided {
+			state.Pending = append(state.Pending, tile.subdivide()...)
+		}
+
+		added := 0
+		for _, store := range result.Data.Stores {
+			if seen[store.StoreNumber] || (opts.Filter != nil && !opts.Filter(store)) {
+				continue
+			}
+			seen[store.StoreNumber] = true
+			state.Stores = append(state.Stores, store)
+			added++
+		}
+
+		if err := state.save(opts.Checkpoint); err != nil {
+			return state.result(), err
+		}
+
+		if opts.OnProgress != nil {
+			opts.OnProgress(SweepProgress{
+				Tile:       tile,
+				Found:      len(result.Data.Stores),
+				New:        added,
+				Subdivided: subdivided,
+				Searches:   state.Searches,
+				Pending:    len(state.Pending),
+				Stores:     len(state.Stores),
+			})
+		}
+	}
+
+	return state.result(), nil
+}
+
+// Private method filling in the defaults of unset options.
+func (opts SweepOptions) withDefaults() SweepOptions {
+	if opts.Radius <= 0 {
+		opts.Radius = DefaultSweepRadius
+	}
+	if opts.DenseThreshold <= 0 {
+		opts.DenseThreshold = DefaultDenseThreshold
+	}
+	if opts.MaxDepth == 0 {
+		opts.MaxDepth = DefaultSweepMaxDepth
+	}
+	return opts
+}
+
+// Private method returning the options that change the searches of a
+// sweep, for the checkpoint area.
+func (opts SweepOptions) key() string {
+	return fmt.Sprintf("%g:%d:%d", opts.Radius, opts.DenseThreshold, opts.MaxDepth)
+}
+
+// Private function covering a box with overlapping circles of the given
+// radius. Each circle is centered on a square cell slightly smaller than
+// radius*sqrt(2) so the cell corners are just inside the circle.
+func tileBox(box BoundingBox, radius float64) []SweepTile {
+	side := radius * math.Sqrt2 * sweepCellFactor
+	latStep := side / milesPerDegreeLatitude
+
+	var tiles []SweepTile
+	for lat := box.SouthWest.Latitude + latStep/2; lat-latStep/2 < box.NorthEast.Latitude; lat += latStep {
+		// Use the widest part of the row so no gaps open up between columns
+		widest := math.Min(math.Abs(lat-latStep/2), math.Abs(lat+latStep/2))
+		lngStep := side / milesPerDegreeLongitude(widest)
+		for lng := box.SouthWest.Longitude + lngStep/2; lng-lngStep/2 < box.NorthEast.Longitude; lng += lngStep {
+			tiles = append(tiles, SweepTile{Center: LatLng{Latitude: lat, Longitude: lng}, Radius: radius})
+		}
+	}
+	return tiles
+}
+
+// Private method splitting a tile into seven tiles covering its whole
+// circle: one on the center and six on a ring sqrt(3)/2 of the radius away.
+// Seven circles of half the radius cover the circle exactly, the children
+// are made slightly larger to leave some overlap.
+func (t SweepTile) subdivide() []SweepTile {
+	child := SweepTile{Center: t.Center, Radius: t.Radius / 2 / sweepCellFactor, Depth: t.Depth + 1}
+	children := []SweepTile{child}
+	ring := t.Radius * math.Sqrt(3) / 2
+	for i := 0; i < 6; i++ {
+		angle := float64(i) * math.Pi / 3
+		child.Center = t.Center.Offset(ring*math.Cos(angle), ring*math.Sin(angle))
+		children = append(children, child)
+	}
+	return children
+}
+
+// Private function loading the checkpoint, or starting a new sweep when
+// there is none.
+func loadSweepState(path string, area string, tiles []SweepTile) (*sweepState, error) {
+	fresh := &sweepState{Area: area, Pending: tiles}
+	if path == "" {
+		return fresh, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return fresh, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var state sweepState
+	if err := unmarshalUnmapped(data, &state); err != nil {
+		return nil, err
+	}
+	if state.Area != area {
+		return nil, fmt.Errorf("%w: %s", ErrCheckpointMismatch, path)
+	}
+	return &state, nil
+}
+
+// Private method writing the checkpoint, if any. The file is replaced
+// atomically so an interrupted write never corrupts it.
+func (s *sweepState) save(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// Private method returning the SweepResult for the current state.
+func (s *sweepState) result() SweepResult {
+	stores := make([]Store, len(s.Stores))
+	copy(stores, s.Stores)
+	sort.Slice(stores, func(i, j int) bool {
+		return stores[i].StoreNumber < stores[j].StoreNumber
+	})
+	return SweepResult{Stores: stores, Searches: s.Searches}
+}
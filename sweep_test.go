@@ -0,0 +1,219 @@
+package riteaid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// Private function starting a server returning the stores within the
+// requested radius of a "lat,lng" address, nearest first and capped at limit.
+func newSweepServer(t *testing.T, stores []Store, limit int, failAfter int32) (*httptest.Server, *int32) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		if failAfter > 0 && n > failAfter {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		parts := strings.Split(r.URL.Query().Get("address"), ",")
+		lat, _ := strconv.ParseFloat(parts[0], 64)
+		lng, _ := strconv.ParseFloat(parts[1], 64)
+		radius, _ := strconv.ParseFloat(r.URL.Query().Get("radius"), 64)
+		center := LatLng{lat, lng}
+
+		var found []Store
+		for _, store := range stores {
+			store.MilesFromCenter = DistanceMiles(center, LatLng{store.Latitude, store.Longitude})
+			if store.MilesFromCenter <= radius {
+				found = append(found, store)
+			}
+		}
+		sort.Slice(found, func(i, j int) bool { return found[i].MilesFromCenter < found[j].MilesFromCenter })
+		if len(found) > limit {
+			found = found[:limit]
+		}
+
+		json.NewEncoder(w).Encode(Result{Status: "SUCCESS", Data: Data{Stores: found, ResolvedAddress: ResolvedAddress{Latitude: lat, Longitude: lng}}})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+// Private function placing a store every 0.05 degrees over a box.
+func gridStores(box BoundingBox) []Store {
+	var stores []Store
+	for lat := box.SouthWest.Latitude; lat <= box.NorthEast.Latitude; lat += 0.05 {
+		for lng := box.SouthWest.Longitude; lng <= box.NorthEast.Longitude; lng += 0.05 {
+			stores = append(stores, Store{StoreNumber: uint32(len(stores) + 1), State: "OH", Latitude: lat, Longitude: lng})
+		}
+	}
+	return stores
+}
+
+func TestSweepBox(t *testing.T) {
+	box := BoundingBox{SouthWest: LatLng{41.0, -83.0}, NorthEast: LatLng{41.5, -82.5}}
+	stores := gridStores(box)
+	srv, calls := newSweepServer(t, stores, 25, 0)
+
+	var progress []SweepProgress
+	client := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(NoRetry))
+	result, err := client.SweepBox(context.Background(), box, SweepOptions{
+		Radius:     10,
+		OnProgress: func(p SweepProgress) { progress = append(progress, p) },
+	})
+	if err != nil {
+		t.Fatalf("SweepBox() ERROR: %q", err)
+	}
+
+	if len(result.Stores) != len(stores) {
+		t.Errorf("SweepBox() found %d stores, want %d", len(result.Stores), len(stores))
+	}
+	for i, store := range result.Stores {
+		if store.StoreNumber != uint32(i+1) {
+			t.Fatalf("SweepBox() store %d = %d, want sorted unique store numbers", i, store.StoreNumber)
+		}
+	}
+
+	subdivided := false
+	for _, p := range progress {
+		subdivided = subdivided || p.Subdivided
+	}
+	if !subdivided {
+		t.Errorf("SweepBox() never subdivided a dense tile")
+	}
+	if int(*calls) != result.Searches || len(progress) != result.Searches {
+		t.Errorf("SweepBox() Searches = %d, calls = %d, progress = %d, want equal", result.Searches, *calls, len(progress))
+	}
+}
+
+func TestSweepResume(t *testing.T) {
+	box := BoundingBox{SouthWest: LatLng{41.0, -83.0}, NorthEast: LatLng{41.5, -82.5}}
+	stores := gridStores(box)
+	checkpoint := filepath.Join(t.TempDir(), "sweep.json")
+	opts := SweepOptions{Radius: 10, Checkpoint: checkpoint}
+
+	// The first run fails part way through
+	srv, _ := newSweepServer(t, stores, 25, 3)
+	client := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(NoRetry))
+	partial, err := client.SweepBox(context.Background(), box, opts)
+	if err == nil || partial.Searches != 3 {
+		t.Fatalf("SweepBox() = %d searches, %v, want 3 searches and an error", partial.Searches, err)
+	}
+
+	// The second run picks up after the third search
+	srv, calls := newSweepServer(t, stores, 25, 0)
+	client = NewClient(WithBaseURL(srv.URL), WithRetryPolicy(NoRetry))
+	result, err := client.SweepBox(context.Background(), box, opts)
+	if err != nil {
+		t.Fatalf("SweepBox(<resume>) ERROR: %q", err)
+	}
+	if len(result.Stores) != len(stores) {
+		t.Errorf("SweepBox(<resume>) found %d stores, want %d", len(result.Stores), len(stores))
+	}
+	if int(*calls) != result.Searches-3 {
+		t.Errorf("SweepBox(<resume>) made %d calls, want %d", *calls, result.Searches-3)
+	}
+
+	// A checkpoint cannot be used for a different area or other options
+	_, err = client.SweepState(context.Background(), "OH", opts)
+	if !errors.Is(err, ErrCheckpointMismatch) {
+		t.Errorf("SweepState(<other checkpoint>) ERROR = %v, want %v", err, ErrCheckpointMismatch)
+	}
+	for _, other := range []SweepOptions{
+		{Radius: 10, Checkpoint: checkpoint, DenseThreshold: 10},
+		{Radius: 10, Checkpoint: checkpoint, MaxDepth: 1},
+	} {
+		if _, err = client.SweepBox(context.Background(), box, other); !errors.Is(err, ErrCheckpointMismatch) {
+			t.Errorf("SweepBox(<%+v>) ERROR = %v, want %v", other, err, ErrCheckpointMismatch)
+		}
+	}
+}
+
+func TestSweepPoints(t *testing.T) {
+	stores := []Store{
+		{StoreNumber: 1, State: "OH", Latitude: 41.05, Longitude: -82.73},
+		{StoreNumber: 2, State: "OH", Latitude: 41.10, Longitude: -82.70},
+		{StoreNumber: 3, State: "PA", Latitude: 40.44, Longitude: -79.99},
+	}
+	srv, _ := newSweepServer(t, stores, 25, 0)
+	client := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(NoRetry))
+
+	// Overlapping points find stores 1 and 2 twice but deliver them once
+	points := []LatLng{{41.05, -82.73}, {41.10, -82.70}}
+	result, err := client.SweepPoints(context.Background(), points, SweepOptions{Radius: 10})
+	if err != nil || fmt.Sprint(storeNumbers(result.Stores)) != "[1 2]" {
+		t.Errorf("SweepPoints() = %v, %v, want [1 2]", storeNumbers(result.Stores), err)
+	}
+
+	if _, err := client.SweepState(context.Background(), "XX", SweepOptions{}); !errors.Is(err, ErrUnknownState) {
+		t.Errorf("SweepState(XX) ERROR = %v, want %v", err, ErrUnknownState)
+	}
+}
+
+func TestSweepTileSubdivide(t *testing.T) {
+	tile := SweepTile{Center: LatLng{41.0428, -82.7258}, Radius: 10}
+	children := tile.subdivide()
+	if len(children) != 7 {
+		t.Fatalf("subdivide() = %d tiles, want 7", len(children))
+	}
+
+	// Every point of the circle, its edge included, is inside a child
+	for _, r := range []float64{0, 2.5, 5, 7.5, 9.5, 10} {
+		for degrees := 0; degrees < 360; degrees += 5 {
+			angle := float64(degrees) * math.Pi / 180
+			p := tile.Center.Offset(r*math.Cos(angle), r*math.Sin(angle))
+			covered := false
+			for _, child := range children {
+				covered = covered || DistanceMiles(child.Center, p) <= child.Radius
+			}
+			if !covered {
+				t.Errorf("subdivide() leaves %s, %g miles at %d degrees, uncovered", p, r, degrees)
+			}
+		}
+	}
+}
+
+func TestSweepPointsDense(t *testing.T) {
+	box := BoundingBox{SouthWest: LatLng{41.0, -83.0}, NorthEast: LatLng{41.5, -82.5}}
+	stores := gridStores(box)
+	srv, _ := newSweepServer(t, stores, 15, 0)
+	client := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(NoRetry))
+
+	// The single search is capped, its subdivisions find the rest
+	center := box.Center()
+	var want []uint32
+	for _, store := range stores {
+		if DistanceMiles(center, store.Location()) <= 10 {
+			want = append(want, store.StoreNumber)
+		}
+	}
+	result, err := client.SweepPoints(context.Background(), []LatLng{center}, SweepOptions{
+		Radius:         10,
+		DenseThreshold: 15,
+		Filter:         func(store Store) bool { return DistanceMiles(center, store.Location()) <= 10 },
+	})
+	if err != nil || fmt.Sprint(storeNumbers(result.Stores)) != fmt.Sprint(want) {
+		t.Errorf("SweepPoints(<dense>) = %d stores, %v, want %d", len(result.Stores), err, len(want))
+	}
+}
+
+// Private function listing the store numbers of stores.
+func storeNumbers(stores []Store) []uint32 {
+	numbers := make([]uint32, len(stores))
+	for i, store := range stores {
+		numbers[i] = store.StoreNumber
+	}
+	return numbers
+}
@@ -0,0 +1,261 @@
+package riteaid
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Error returned when the time zone of a store cannot be resolved
+var ErrUnknownTimeZone = errors.New("store time zone could not be resolved")
+
+// TimeZoneResolver finds the location whose clock a store's hours are kept
+// in. See DefaultTimeZoneResolver.
+type TimeZoneResolver interface {
+	// Location returns the location of the store, or an error wrapping
+	// ErrUnknownTimeZone when the resolver has nothing to go on.
+	Location(store Store) (*time.Location, error)
+}
+
+// TimeZoneResolverFunc adapts a function to a TimeZoneResolver.
+type TimeZoneResolverFunc func(store Store) (*time.Location, error)
+
+// Location calls f(store).
+func (f TimeZoneResolverFunc) Location(store Store) (*time.Location, error) {
+	return f(store)
+}
+
+var (
+	// StoreTimeZone resolves the TimeZone field returned by the API. Both
+	// IANA names, i.e. "America/New_York", and US abbreviations, i.e. "EST",
+	// are understood. Abbreviations follow daylight saving time, "EST" being
+	// the Eastern zone and not a fixed UTC-5 offset.
+	StoreTimeZone TimeZoneResolver = TimeZoneResolverFunc(storeTimeZone)
+
+	// CoordinateTimeZone resolves the Latitude and Longitude of the store,
+	// see GetTZLocationLatLng.
+	CoordinateTimeZone TimeZoneResolver = TimeZoneResolverFunc(coordinateTimeZone)
+
+	// StateTimeZone resolves the State of the store, refined by ZIP code in
+	// the states spanning more than one zone.
+	StateTimeZone TimeZoneResolver = TimeZoneResolverFunc(stateTimeZone)
+
+	// DefaultTimeZoneResolver is used when no other resolver is chosen. It
+	// tries the store's TimeZone field, then its coordinates, then its state.
+	DefaultTimeZoneResolver = FirstTimeZone(StoreTimeZone, CoordinateTimeZone, StateTimeZone)
+)
+
+// FixedTimeZone returns a TimeZoneResolver placing every store in loc.
+//
+//	hours := riteaid.Hours{TimeZone: riteaid.FixedTimeZone(time.Local)}
+func FixedTimeZone(loc *time.Location) TimeZoneResolver {
+	return TimeZoneResolverFunc(func(Store) (*time.Location, error) {
+		return loc, nil
+	})
+}
+
+// FirstTimeZone returns a TimeZoneResolver trying each resolver in order
+// and returning the first location found. The error of the last resolver is
+// returned when none succeed.
+//
+//	resolver := riteaid.FirstTimeZone(riteaid.StoreTimeZone, riteaid.StateTimeZone)
+func FirstTimeZone(resolvers ...TimeZoneResolver) TimeZoneResolver {
+	return TimeZoneResolverFunc(func(store Store) (*time.Location, error) {
+		err := ErrUnknownTimeZone
+		for _, resolver := range resolvers {
+			var loc *time.Location
+			if loc, err = resolver.Location(store); err == nil {
+				return loc, nil
+			}
+		}
+		return nil, err
+	})
+}
+
+// WithTimeZoneResolver sets the TimeZoneResolver used by the Client's Hours.
+// Without it DefaultTimeZoneResolver is used.
+func WithTimeZoneResolver(resolver TimeZoneResolver) Option {
+	return func(c *Client) {
+		c.timeZone = resolver
+	}
+}
+
+// Private table of the US time zone abbreviations used by the API
+var timeZoneAbbreviations = map[string]string{
+	"ET":   "America/New_York",
+	"EST":  "America/New_York",
+	"EDT":  "America/New_York",
+	"CT":   "America/Chicago",
+	"CST":  "America/Chicago",
+	"CDT":  "America/Chicago",
+	"MT":   "America/Denver",
+	"MST":  "America/Denver",
+	"MDT":  "America/Denver",
+	"PT":   "America/Los_Angeles",
+	"PST":  "America/Los_Angeles",
+	"PDT":  "America/Los_Angeles",
+	"AKST": "America/Anchorage",
+	"AKDT": "America/Anchorage",
+	"HST":  "Pacific/Honolulu",
+}
+
+// Private function implementing StoreTimeZone
+func storeTimeZone(store Store) (*time.Location, error) {
+	name := strings.TrimSpace(store.TimeZone)
+	if name == "" {
+		return nil, fmt.Errorf("%w: store %d has no TimeZone", ErrUnknownTimeZone, store.StoreNumber)
+	}
+	if iana, ok := timeZoneAbbreviations[strings.ToUpper(name)]; ok {
+		// Arizona keeps standard time all year
+		if iana == "America/Denver" && strings.EqualFold(store.State, "AZ") {
+			iana = "America/Phoenix"
+		}
+		name = iana
+	} else if !strings.Contains(name, "/") {
+		return nil, fmt.Errorf("%w: TimeZone %q", ErrUnknownTimeZone, store.TimeZone)
+	}
+	return time.LoadLocation(name)
+}
+
+// Private function implementing CoordinateTimeZone
+func coordinateTimeZone(store Store) (*time.Location, error) {
+	if store.Latitude == 0 && store.Longitude == 0 {
+		return nil, fmt.Errorf("%w: store %d has no coordinates", ErrUnknownTimeZone, store.StoreNumber)
+	}
+	return GetTZLocationLatLng(store.Location())
+}
+
+// Private table of the time zone of each state. States spanning more than
+// one zone are listed with the zone most of their population lives in.
+var stateTimeZones = map[string]string{
+	"AL": "America/Chicago", "AK": "America/Anchorage", "AZ": "America/Phoenix",
+	"AR": "America/Chicago", "CA": "America/Los_Angeles", "CO": "America/Denver",
+	"CT": "America/New_York", "DC": "America/New_York", "DE": "America/New_York",
+	"FL": "America/New_York", "GA": "America/New_York", "HI": "Pacific/Honolulu",
+	"IA": "America/Chicago", "ID": "America/Boise", "IL": "America/Chicago",
+	"IN": "America/Indiana/Indianapolis", "KS": "America/Chicago", "KY": "America/New_York",
+	"LA": "America/Chicago", "MA": "America/New_York", "MD": "America/New_York",
+	"ME": "America/New_York", "MI": "America/Detroit", "MN": "America/Chicago",
+	"MO": "America/Chicago", "MS": "America/Chicago", "MT": "America/Denver",
+	"NC": "America/New_York", "ND": "America/Chicago", "NE": "America/Chicago",
+	"NH": "America/New_York", "NJ": "America/New_York", "NM": "America/Denver",
+	"NV": "America/Los_Angeles", "NY": "America/New_York", "OH": "America/New_York",
+	"OK": "America/Chicago", "OR": "America/Los_Angeles", "PA": "America/New_York",
+	"RI": "America/New_York", "SC": "America/New_York", "SD": "America/Chicago",
+	"TN": "America/Chicago", "TX": "America/Chicago", "UT": "America/Denver",
+	"VA": "America/New_York", "VT": "America/New_York", "WA": "America/Los_Angeles",
+	"WI": "America/Chicago", "WV": "America/New_York", "WY": "America/Denver",
+}
+
+// Private table of the 3 digit ZIP code prefixes whose zone differs from
+// the rest of their state
+var zipTimeZones = map[string]string{
+	"324": "America/Chicago",     // Florida panhandle
+	"325": "America/Chicago",     // Florida panhandle
+	"463": "America/Chicago",     // Northwest Indiana
+	"464": "America/Chicago",     // Northwest Indiana
+	"420": "America/Chicago",     // Western Kentucky
+	"421": "America/Chicago",     // Western Kentucky
+	"422": "America/Chicago",     // Western Kentucky
+	"423": "America/Chicago",     // Western Kentucky
+	"424": "America/Chicago",     // Western Kentucky
+	"427": "America/Chicago",     // Western Kentucky
+	"376": "America/New_York",    // East Tennessee
+	"377": "America/New_York",    // East Tennessee
+	"378": "America/New_York",    // East Tennessee
+	"379": "America/New_York",    // East Tennessee
+	"798": "America/Denver",      // El Paso, Texas
+	"799": "America/Denver",      // El Paso, Texas
+	"835": "America/Los_Angeles", // North Idaho
+	"838": "America/Los_Angeles", // North Idaho
+	"979": "America/Boise",       // Eastern Oregon
+}
+
+// Private function implementing StateTimeZone
+func stateTimeZone(store Store) (*time.Location, error) {
+	zip := store.Zipcode
+	if zip == "" {
+		zip = store.FullZipCode
+	}
+	if len(zip) >= 3 {
+		if name, ok := zipTimeZones[zip[:3]]; ok {
+			return time.LoadLocation(name)
+		}
+	}
+	if name, ok := stateTimeZones[strings.ToUpper(strings.TrimSpace(store.State))]; ok {
+		return time.LoadLocation(name)
+	}
+	return nil, fmt.Errorf("%w: state %q", ErrUnknownTimeZone, store.State)
+}
+
+// TimeZoneMismatch describes a store whose TimeZone field, as returned by
+// the API, keeps a different clock than the zone of its coordinates.
+type TimeZoneMismatch struct {
+	StoreNumber uint32
+
+	// The TimeZone field returned by the API, i.e. "EST"
+	TimeZone string
+
+	// The location of the TimeZone field and of the coordinates
+	Store       *time.Location
+	Coordinates *time.Location
+}
+
+// String returns a one line description of the mismatch.
+func (m TimeZoneMismatch) String() string {
+	return fmt.Sprintf("store %d: API time zone %q (%s) disagrees with its coordinates (%s)", m.StoreNumber, m.TimeZone, m.Store, m.Coordinates)
+}
+
+// CheckTimeZone compares the TimeZone field of a store with the zone of its
+// coordinates. Zones are compared by their UTC offsets through the year, so
+// "EST" and "America/Detroit" agree. False is returned when they agree or
+// when either cannot be resolved.
+//
+//	if mismatch, ok := riteaid.CheckTimeZone(store); ok {
+//		log.Println(mismatch)
+//	}
+func CheckTimeZone(store Store) (TimeZoneMismatch, bool) {
+	fromStore, err := StoreTimeZone.Location(store)
+	if err != nil {
+		return TimeZoneMismatch{}, false
+	}
+	fromCoordinates, err := CoordinateTimeZone.Location(store)
+	if err != nil {
+		return TimeZoneMismatch{}, false
+	}
+	if sameClock(fromStore, fromCoordinates) {
+		return TimeZoneMismatch{}, false
+	}
+	return TimeZoneMismatch{
+		StoreNumber: store.StoreNumber,
+		TimeZone:    store.TimeZone,
+		Store:       fromStore,
+		Coordinates: fromCoordinates,
+	}, true
+}
+
+// WithTimeZoneMismatchHandler sets a function called for every store of a
+// successful API response whose TimeZone field disagrees with its
+// coordinates, see CheckTimeZone. Responses served from a Cache are not
+// checked again.
+func WithTimeZoneMismatchHandler(fn func(TimeZoneMismatch)) Option {
+	return func(c *Client) {
+		c.onTimeZoneMismatch = fn
+	}
+}
+
+// Private function returning true if both locations are at the same UTC
+// offset in winter and in summer of the current year.
+func sameClock(a *time.Location, b *time.Location) bool {
+	year := time.Now().Year()
+	for _, month := range []time.Month{time.January, time.July} {
+		t := time.Date(year, month, 15, 12, 0, 0, 0, time.UTC)
+		_, offsetA := t.In(a).Zone()
+		_, offsetB := t.In(b).Zone()
+		if offsetA != offsetB {
+			return false
+		}
+	}
+	return true
+}
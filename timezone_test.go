@@ -0,0 +1,124 @@
+package riteaid
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeZoneResolvers(t *testing.T) {
+	tests := []struct {
+		name     string
+		resolver TimeZoneResolver
+		store    Store
+		want     string
+	}{
+		{"abbreviation", StoreTimeZone, Store{TimeZone: "EST"}, "America/New_York"},
+		{"arizona", StoreTimeZone, Store{TimeZone: "MST", State: "AZ"}, "America/Phoenix"},
+		{"iana", StoreTimeZone, Store{TimeZone: "America/Detroit"}, "America/Detroit"},
+		{"coordinates", CoordinateTimeZone, Store{Latitude: 34.0522, Longitude: -118.2437}, "America/Los_Angeles"},
+		{"state", StateTimeZone, Store{State: "oh"}, "America/New_York"},
+		{"zip", StateTimeZone, Store{State: "FL", Zipcode: "32501"}, "America/Chicago"},
+		{"fixed", FixedTimeZone(time.UTC), Store{TimeZone: "EST"}, "UTC"},
+		{"default store first", DefaultTimeZoneResolver, Store{TimeZone: "PST", Latitude: 41.0428, Longitude: -82.7258}, "America/Los_Angeles"},
+		{"default coordinates", DefaultTimeZoneResolver, Store{Latitude: 41.0428, Longitude: -82.7258, State: "CA"}, "America/New_York"},
+		{"default state", DefaultTimeZoneResolver, Store{State: "WA"}, "America/Los_Angeles"},
+	}
+	for _, test := range tests {
+		loc, err := test.resolver.Location(test.store)
+		if err != nil || loc.String() != test.want {
+			t.Errorf("%s: Location() = %v, %v, want %s", test.name, loc, err, test.want)
+		}
+	}
+
+	for _, store := range []Store{{}, {TimeZone: "Mars"}} {
+		if _, err := DefaultTimeZoneResolver.Location(store); !errors.Is(err, ErrUnknownTimeZone) {
+			t.Errorf("Location(%+v) ERROR = %v, want %v", store, err, ErrUnknownTimeZone)
+		}
+	}
+}
+
+func TestHoursTimeZone(t *testing.T) {
+	storeData := scheduleStore()
+	storeData.TimeZone = "CST"
+	storeData.Latitude, storeData.Longitude = 41.0428, -82.7258
+
+	// Monday 2022-05-30 8:30am Eastern is 7:30am Central, before opening
+	at := time.Date(2022, 5, 30, 12, 30, 0, 0, time.UTC)
+	if storeOpen, _, err := IsStoreOpen(at, storeData); err != nil || storeOpen {
+		t.Errorf("IsStoreOpen(<CST>) = %t, %v, want false", storeOpen, err)
+	}
+	if storeOpen, _, err := (Hours{TimeZone: CoordinateTimeZone}).IsStoreOpen(at, storeData); err != nil || !storeOpen {
+		t.Errorf("Hours{<coordinates>}.IsStoreOpen() = %t, %v, want true", storeOpen, err)
+	}
+	hours := NewClient(WithTimeZoneResolver(CoordinateTimeZone)).Hours()
+	storeHours, _, err := hours.GetStoreHours("2022-05-30", storeData)
+	if err != nil || storeHours[0].Location().String() != "America/New_York" {
+		t.Errorf("Client.Hours().GetStoreHours() = %s, %v, want America/New_York", storeHours, err)
+	}
+	if _, _, err := (Hours{TimeZone: StateTimeZone}).GetStoreHours("2022-05-30", storeData); !errors.Is(err, ErrUnknownTimeZone) {
+		t.Errorf("Hours{<no state>}.GetStoreHours() ERROR = %v, want %v", err, ErrUnknownTimeZone)
+	}
+}
+
+func TestCheckTimeZone(t *testing.T) {
+	store := Store{StoreNumber: 3357, TimeZone: "EST", Latitude: 41.0524, Longitude: -82.7255}
+	if mismatch, ok := CheckTimeZone(store); ok {
+		t.Errorf("CheckTimeZone(<EST in Ohio>) = %s, want no mismatch", mismatch)
+	}
+	store.TimeZone = "CST"
+	mismatch, ok := CheckTimeZone(store)
+	if !ok || mismatch.Store.String() != "America/Chicago" || mismatch.Coordinates.String() != "America/New_York" {
+		t.Errorf("CheckTimeZone(<CST in Ohio>) = %s, %t, want a mismatch", mismatch, ok)
+	}
+
+	data, err := json.Marshal(Result{Status: "SUCCESS", Data: Data{Stores: []Store{store}}})
+	if err != nil {
+		t.Fatalf("json.Marshal() ERROR: %q", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	var reported []TimeZoneMismatch
+	client := NewClient(WithBaseURL(srv.URL), WithTimeZoneMismatchHandler(func(m TimeZoneMismatch) { reported = append(reported, m) }))
+	if _, err := client.Search("4 Walton St E, Willard, OH 44890", 0.5); err != nil {
+		t.Fatalf("Search() ERROR: %q", err)
+	}
+	if len(reported) != 1 || reported[0].StoreNumber != 3357 || reported[0].TimeZone != "CST" {
+		t.Errorf("WithTimeZoneMismatchHandler() reported %v, want store 3357", reported)
+	}
+}
+
+func TestParseWeekDayHoursLatLng(t *testing.T) {
+	// Los Angeles, where the deprecated longitude first order must still resolve the same zone
+	start, end, err := ParseWeekDayHoursLatLng(time.Monday, "8:00am-5:00pm", LatLng{Latitude: 34.0522, Longitude: -118.2437})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start.Location().String() != "America/Los_Angeles" || start.Weekday() != time.Monday || start.Hour() != 8 || end.Hour() != 17 {
+		t.Errorf("ParseWeekDayHoursLatLng = %v - %v", start, end)
+	}
+	oldStart, oldEnd, err := ParseWeekDayHours(time.Monday, "8:00am-5:00pm", -118.2437, 34.0522)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !oldStart.Equal(start) || !oldEnd.Equal(end) || oldStart.Location().String() != start.Location().String() {
+		t.Errorf("ParseWeekDayHours = %v - %v, want %v - %v", oldStart, oldEnd, start, end)
+	}
+}
+
+func TestGetTZLocationLatLng(t *testing.T) {
+	// Los Angeles, where the deprecated longitude first order must still resolve the same zone
+	loc, err := GetTZLocationLatLng(LatLng{Latitude: 34.0522, Longitude: -118.2437})
+	if err != nil || loc.String() != "America/Los_Angeles" {
+		t.Fatalf("GetTZLocationLatLng() = %v, %v, want America/Los_Angeles", loc, err)
+	}
+	if loc, err := GetTZLocation(-118.2437, 34.0522); err != nil || loc.String() != "America/Los_Angeles" {
+		t.Errorf("GetTZLocation(<longitude>, <latitude>) = %v, %v, want America/Los_Angeles", loc, err)
+	}
+}